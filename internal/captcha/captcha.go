@@ -0,0 +1,89 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tiger-fasttrack-card/internal/i18n"
+)
+
+// ErrFailed is returned when the token is missing or rejected by the provider
+var ErrFailed = i18n.NewError("captcha_failed")
+
+// Verifier checks a CAPTCHA response token submitted by a client
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Default verification endpoints of the supported providers
+var providerURLs = map[string]string{
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// SiteVerifier implements the "siteverify" protocol shared by reCAPTCHA, hCaptcha and Turnstile
+type SiteVerifier struct {
+	VerifyURL string
+	Secret    string
+	client    *http.Client
+}
+
+// NewVerifier returns a verifier for provider ("recaptcha", "hcaptcha" or "turnstile");
+// verifyURL overrides the provider's endpoint, e.g. for a self-hosted or test service
+func NewVerifier(provider, secret, verifyURL string) (*SiteVerifier, error) {
+	if verifyURL == "" {
+		verifyURL = providerURLs[strings.ToLower(provider)]
+	}
+	if verifyURL == "" {
+		return nil, fmt.Errorf("unknown captcha provider %q", provider)
+	}
+	if secret == "" {
+		return nil, errors.New("captcha secret is required")
+	}
+	return &SiteVerifier{
+		VerifyURL: verifyURL,
+		Secret:    secret,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrFailed
+	}
+
+	form := url.Values{"secret": {v.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha provider unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid captcha provider response: %w", err)
+	}
+	if !result.Success {
+		return ErrFailed
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cardnumber
+
+import (
+	"strconv"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"unicode"
+)
+
+// Check digit algorithms
+const (
+	CheckDigitNone = "none"
+	CheckDigitLuhn = "luhn"
+)
+
+// ErrExhausted is returned when a sequence no longer fits the scheme's length
+var ErrExhausted = i18n.NewError("card_numbers_exhausted")
+
+// Scheme describes how the numbers of a card are built: the prefix, a zero-padded
+// sequence and an optional check digit, Length digits in total
+type Scheme struct {
+	Prefix     string
+	Length     int    // 0 means the card has no scheme and numbers are free-form
+	CheckDigit string // CheckDigitNone or CheckDigitLuhn
+}
+
+// Enabled reports whether numbers are generated and validated by the scheme
+func (s Scheme) Enabled() bool {
+	return s.Length > 0
+}
+
+// Validate checks that the scheme itself is usable
+func (s Scheme) Validate() error {
+	if !s.Enabled() {
+		return nil
+	}
+	if !isDigits(s.Prefix) && s.Prefix != "" {
+		return i18n.NewError("invalid_number_prefix")
+	}
+	if s.CheckDigit != "" && s.CheckDigit != CheckDigitNone && s.CheckDigit != CheckDigitLuhn {
+		return i18n.NewError("invalid_check_digit")
+	}
+	if s.sequenceDigits() < 1 || s.Length > 32 {
+		return i18n.NewError("invalid_number_length")
+	}
+	return nil
+}
+
+// Capacity is the highest sequence the scheme can format
+func (s Scheme) Capacity() int64 {
+	digits := s.sequenceDigits()
+	if digits > 18 {
+		digits = 18
+	}
+	capacity := int64(1)
+	for i := 0; i < digits; i++ {
+		capacity *= 10
+	}
+	return capacity - 1
+}
+
+// Format builds the number for a sequence
+func (s Scheme) Format(sequence int64) (string, error) {
+	if sequence < 1 || sequence > s.Capacity() {
+		return "", ErrExhausted
+	}
+	seq := strconv.FormatInt(sequence, 10)
+	number := s.Prefix + strings.Repeat("0", s.sequenceDigits()-len(seq)) + seq
+	if s.CheckDigit == CheckDigitLuhn {
+		number += string(LuhnDigit(number))
+	}
+	return number, nil
+}
+
+// Check validates a normalized number against the scheme
+func (s Scheme) Check(number string) error {
+	if number == "" {
+		return i18n.NewError("card_number_required")
+	}
+	if !s.Enabled() {
+		return nil
+	}
+	if !isDigits(number) {
+		return i18n.NewError("card_number_not_numeric")
+	}
+	if len(number) != s.Length {
+		return i18n.NewError("card_number_length", s.Length)
+	}
+	if !strings.HasPrefix(number, s.Prefix) {
+		return i18n.NewError("card_number_prefix", s.Prefix)
+	}
+	if s.CheckDigit == CheckDigitLuhn && !ValidLuhn(number) {
+		return i18n.NewError("card_number_check_digit")
+	}
+	return nil
+}
+
+// Normalize removes the spaces and dashes people use to group digits
+func Normalize(number string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return r
+	}, number)
+}
+
+// LuhnDigit returns the check digit that makes payload+digit pass the Luhn check
+func LuhnDigit(payload string) byte {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		// Doubled positions are counted from the check digit, which is appended to the right
+		if (len(payload)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// ValidLuhn reports whether number ends in a valid Luhn check digit
+func ValidLuhn(number string) bool {
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	return LuhnDigit(number[:len(number)-1]) == number[len(number)-1]
+}
+
+func (s Scheme) sequenceDigits() int {
+	digits := s.Length - len(s.Prefix)
+	if s.CheckDigit == CheckDigitLuhn {
+		digits--
+	}
+	return digits
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
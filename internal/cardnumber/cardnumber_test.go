@@ -0,0 +1,127 @@
+package cardnumber
+
+import (
+	"errors"
+	"testing"
+
+	"tiger-fasttrack-card/internal/i18n"
+)
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		number string
+		valid  bool
+	}{
+		{number: "79927398713", valid: true},
+		{number: "4111111111111111", valid: true},
+		{number: "4539578763621486", valid: true},
+		{number: "4101000000000018", valid: true},
+		{number: "00", valid: true},
+		{number: "79927398710"},
+		{number: "4111111111111112"},
+		{number: "4111-1111-1111-1111"},
+		{number: "0"},
+		{number: ""},
+	}
+	for _, tt := range tests {
+		if got := ValidLuhn(tt.number); got != tt.valid {
+			t.Errorf("ValidLuhn(%q) = %v, want %v", tt.number, got, tt.valid)
+		}
+		if tt.valid {
+			payload := tt.number[:len(tt.number)-1]
+			if got := LuhnDigit(payload); got != tt.number[len(tt.number)-1] {
+				t.Errorf("LuhnDigit(%q) = %c, want %c", payload, got, tt.number[len(tt.number)-1])
+			}
+		}
+	}
+}
+
+func TestSchemeFormat(t *testing.T) {
+	luhn := Scheme{Prefix: "4101", Length: 16, CheckDigit: CheckDigitLuhn}
+	plain := Scheme{Prefix: "42", Length: 6, CheckDigit: CheckDigitNone}
+
+	tests := []struct {
+		name     string
+		scheme   Scheme
+		sequence int64
+		want     string
+		wantErr  error
+	}{
+		{name: "first luhn number", scheme: luhn, sequence: 1, want: "4101000000000018"},
+		{name: "last luhn number", scheme: luhn, sequence: 99999999999, want: "4101999999999991"},
+		{name: "luhn exhausted", scheme: luhn, sequence: 100000000000, wantErr: ErrExhausted},
+		{name: "plain number", scheme: plain, sequence: 42, want: "420042"},
+		{name: "plain exhausted", scheme: plain, sequence: 10000, wantErr: ErrExhausted},
+		{name: "sequence starts at one", scheme: plain, sequence: 0, wantErr: ErrExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scheme.Format(tt.sequence)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Format(%d) = %q, %v, want %q, %v", tt.sequence, got, err, tt.want, tt.wantErr)
+			}
+			if err == nil {
+				if err := tt.scheme.Check(got); err != nil {
+					t.Errorf("Check(%q) = %v for a formatted number", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemeCheck(t *testing.T) {
+	luhn := Scheme{Prefix: "4101", Length: 16, CheckDigit: CheckDigitLuhn}
+
+	tests := []struct {
+		name     string
+		scheme   Scheme
+		number   string
+		wantCode string
+	}{
+		{name: "valid", scheme: luhn, number: "4101000000000018"},
+		{name: "free-form without a scheme", scheme: Scheme{}, number: "ABC-123"},
+		{name: "empty", scheme: luhn, number: "", wantCode: "card_number_required"},
+		{name: "letters", scheme: luhn, number: "41010000000000A8", wantCode: "card_number_not_numeric"},
+		{name: "too short", scheme: luhn, number: "410100000000018", wantCode: "card_number_length"},
+		{name: "other prefix", scheme: luhn, number: "4111111111111111", wantCode: "card_number_prefix"},
+		{name: "wrong check digit", scheme: luhn, number: "4101000000000019", wantCode: "card_number_check_digit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scheme.Check(tt.number)
+			var coded *i18n.Error
+			switch {
+			case tt.wantCode == "" && err != nil:
+				t.Errorf("Check(%q) = %v, want nil", tt.number, err)
+			case tt.wantCode != "" && (!errors.As(err, &coded) || coded.Code != tt.wantCode):
+				t.Errorf("Check(%q) = %v, want %s", tt.number, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSchemeValidate(t *testing.T) {
+	tests := []struct {
+		scheme  Scheme
+		wantErr bool
+	}{
+		{scheme: Scheme{}},
+		{scheme: Scheme{Prefix: "4101", Length: 16, CheckDigit: CheckDigitLuhn}},
+		{scheme: Scheme{Length: 8}},
+		{scheme: Scheme{Prefix: "41A", Length: 16}, wantErr: true},
+		{scheme: Scheme{Prefix: "4101", Length: 16, CheckDigit: "mod97"}, wantErr: true},
+		{scheme: Scheme{Prefix: "4101", Length: 5, CheckDigit: CheckDigitLuhn}, wantErr: true},
+		{scheme: Scheme{Length: 33}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.scheme.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() = %v, want error %v", tt.scheme, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(" 4101-0000 0000\t0018 "); got != "4101000000000018" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
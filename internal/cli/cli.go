@@ -0,0 +1,237 @@
+// Package cli implements the operator commands of the binary: creating users, resetting
+// passwords, importing cards and seeding. main wires the configuration, database and
+// services they share with the server.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"tiger-fasttrack-card/internal/config"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+	"tiger-fasttrack-card/internal/seed"
+	"tiger-fasttrack-card/internal/service"
+)
+
+// Usage lists the commands, one per line, for the binary's usage message
+const Usage = `user create --username name --role role [--organization code] [--branch code] [--email address] [--phone number] [--first-name name] [--last-name name] (--password-stdin | --password password)
+user reset-password --username name (--password-stdin | --password password)
+card import file.json|file.csv
+seed [--owners count] [--random-seed n] [--password password]`
+
+// Env holds what the commands work with
+type Env struct {
+	Config  *config.Config
+	Repo    *repository.Repository
+	Service *service.Service
+	Stdin   io.Reader // Passwords given with --password-stdin
+	Stdout  io.Writer // Results, as JSON
+	Stderr  io.Writer // Errors and flag usage
+}
+
+// ErrUnknownCommand is returned by Run for commands it doesn't implement
+var ErrUnknownCommand = errors.New("unknown command")
+
+// errUsage reports invalid arguments; the flag package has already explained them
+var errUsage = errors.New("invalid arguments")
+
+// Run runs the command in args and returns the exit code: 0 on success, 1 when the command
+// failed and 2 for invalid arguments
+func Run(ctx context.Context, env Env, args []string) int {
+	err := run(ctx, env, args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, ErrUnknownCommand):
+		fmt.Fprintf(env.Stderr, "unknown command %q\n", strings.Join(args, " "))
+		return 2
+	}
+	fmt.Fprintln(env.Stderr, "Error:", err)
+	return 1
+}
+
+func run(ctx context.Context, env Env, args []string) error {
+	if len(args) == 0 {
+		return ErrUnknownCommand
+	}
+	switch args[0] {
+	case "user":
+		if len(args) >= 2 && args[1] == "create" {
+			return createUser(ctx, env, args[2:])
+		}
+		if len(args) >= 2 && args[1] == "reset-password" {
+			return resetPassword(ctx, env, args[2:])
+		}
+	case "card":
+		if len(args) >= 2 && args[1] == "import" {
+			return importCards(ctx, env, args[2:])
+		}
+	case "seed":
+		return runSeed(ctx, env, args[1:])
+	}
+	return ErrUnknownCommand
+}
+
+func createUser(ctx context.Context, env Env, args []string) error {
+	flags := newFlagSet(env, "user create")
+	var req models.CreateUserRequest
+	flags.StringVar(&req.Username, "username", "", "login name (required)")
+	flags.StringVar(&req.Role, "role", "user", "user, admin or super_admin")
+	flags.StringVar(&req.OrganizationCode, "organization", "", "organization code (default: the default organization; not for super admins)")
+	flags.StringVar(&req.BranchCode, "branch", "", "branch code")
+	flags.StringVar(&req.Email, "email", "", "email address, used for password reset")
+	flags.StringVar(&req.Phone, "phone", "", "phone number")
+	flags.StringVar(&req.FirstName, "first-name", "", "first name")
+	flags.StringVar(&req.LastName, "last-name", "", "last name")
+	password := passwordFlags(flags)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if req.Username == "" {
+		return usageError(flags, "--username is required")
+	}
+
+	var err error
+	if req.Password, err = password.read(env); err != nil {
+		return err
+	}
+	user, err := env.Service.CreateUser(ctx, &req)
+	if err != nil {
+		return err
+	}
+	return printJSON(env, user)
+}
+
+func resetPassword(ctx context.Context, env Env, args []string) error {
+	flags := newFlagSet(env, "user reset-password")
+	username := flags.String("username", "", "login name (required)")
+	password := passwordFlags(flags)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return usageError(flags, "--username is required")
+	}
+
+	newPassword, err := password.read(env)
+	if err != nil {
+		return err
+	}
+	user, err := env.Service.ResetUserPassword(ctx, *username, newPassword)
+	if err != nil {
+		return err
+	}
+	return printJSON(env, user)
+}
+
+func importCards(ctx context.Context, env Env, args []string) error {
+	flags := newFlagSet(env, "card import")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "expected one file to import")
+	}
+
+	cards, err := readCards(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	result, err := env.Service.ImportCards(ctx, cards)
+	if result != nil {
+		printJSON(env, result)
+	}
+	return err
+}
+
+func runSeed(ctx context.Context, env Env, args []string) error {
+	flags := newFlagSet(env, "seed")
+	var opts seed.Options
+	flags.StringVar(&opts.Password, "password", seed.DefaultPassword, "password of the seeded users")
+	flags.IntVar(&opts.Owners, "owners", 50, "number of card holders to generate")
+	flags.Uint64Var(&opts.RandomSeed, "random-seed", 1, "seed of the generated card holders")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	// The seeded users have well-known passwords
+	if env.Config.Environment == "production" {
+		return errors.New("seeding is disabled in production")
+	}
+
+	report, err := seed.New(env.Repo, env.Service).Run(ctx, opts)
+	if report != nil {
+		printJSON(env, report)
+	}
+	return err
+}
+
+func newFlagSet(env Env, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(env.Stderr)
+	return flags
+}
+
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+func usageError(flags *flag.FlagSet, msg string) error {
+	fmt.Fprintln(flags.Output(), msg)
+	flags.Usage()
+	return errUsage
+}
+
+// passwordSource is where a command takes a password from; --password is visible to other
+// users of the machine and kept in shell history, so scripts should prefer --password-stdin
+type passwordSource struct {
+	flags     *flag.FlagSet
+	value     *string
+	fromStdin *bool
+}
+
+func passwordFlags(flags *flag.FlagSet) passwordSource {
+	return passwordSource{
+		flags:     flags,
+		value:     flags.String("password", "", "password; prefer --password-stdin"),
+		fromStdin: flags.Bool("password-stdin", false, "read the password from the first line of standard input"),
+	}
+}
+
+func (p passwordSource) read(env Env) (string, error) {
+	switch {
+	case *p.fromStdin && *p.value != "":
+		return "", usageError(p.flags, "--password and --password-stdin can't be used together")
+	case *p.value != "":
+		return *p.value, nil
+	case !*p.fromStdin:
+		return "", usageError(p.flags, "a password is required, use --password-stdin")
+	}
+
+	line, err := bufio.NewReader(env.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("no password on standard input")
+	}
+	return password, nil
+}
+
+func printJSON(env Env, v any) error {
+	encoder := json.NewEncoder(env.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
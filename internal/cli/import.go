@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tiger-fasttrack-card/internal/models"
+)
+
+// readCards reads the cards to import from a JSON array or a CSV file with a header row,
+// both using the field names of the create card request. In CSV, benefits are separated
+// by "|". Card images given as a path relative to the file are embedded as data URLs
+func readCards(path string) ([]models.CreateCardRequest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cards []models.CreateCardRequest
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(file)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cards); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case ".csv":
+		if cards, err = readCardsCSV(file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s must end in .json or .csv", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range cards {
+		image, err := embedImage(dir, cards[i].CardImage)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cards[i].CardName, err)
+		}
+		cards[i].CardImage = image
+	}
+	return cards, nil
+}
+
+// readCardsCSV converts each row to the JSON the API accepts, so both formats are decoded
+// by the same rules
+func readCardsCSV(r io.Reader) ([]models.CreateCardRequest, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	cards := make([]models.CreateCardRequest, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		fields := make(map[string]any)
+		for i, column := range header {
+			value := strings.TrimSpace(row[i])
+			if value == "" {
+				continue
+			}
+			switch column = strings.TrimSpace(column); column {
+			case "price", "display_order", "number_length":
+				if fields[column], err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("line %d: %s must be a whole number", line+2, column)
+				}
+			case "is_active":
+				if fields[column], err = strconv.ParseBool(value); err != nil {
+					return nil, fmt.Errorf("line %d: is_active must be true or false", line+2)
+				}
+			case "benefits":
+				fields[column] = strings.Split(value, "|")
+			default:
+				fields[column] = value
+			}
+		}
+
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.DisallowUnknownFields()
+		var card models.CreateCardRequest
+		if err := decoder.Decode(&card); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// embedImage turns an image path relative to dir into a data URL; URLs, including paths
+// starting with "/", and data URLs are kept as they are
+func embedImage(dir, image string) (string, error) {
+	if image == "" || strings.Contains(image, "://") || strings.HasPrefix(image, "/") || strings.HasPrefix(image, "data:") {
+		return image, nil
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(image)))
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("card image %s must be a URL or an image file", image)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, image))
+	if err != nil {
+		return "", fmt.Errorf("failed to read card image: %w", err)
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
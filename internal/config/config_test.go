@@ -0,0 +1,257 @@
+package config
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validProduction returns production defaults with the secrets production requires set
+func validProduction() *Config {
+	cfg := Default("production")
+	cfg.JWTSecret = "a-strong-secret-only-used-in-tests"
+	cfg.JWT.KeyEncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	return cfg
+}
+
+func TestDefaultsValidate(t *testing.T) {
+	for _, environment := range []string{"development", "test"} {
+		if err := Default(environment).Validate(); err != nil {
+			t.Errorf("Default(%q).Validate() = %v", environment, err)
+		}
+	}
+	if err := validProduction().Validate(); err != nil {
+		t.Errorf("production defaults with secrets: Validate() = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(cfg *Config)
+		wantErr string // substring of the error; empty for a valid configuration
+	}{
+		{
+			name:    "unknown environment",
+			change:  func(cfg *Config) { cfg.Environment = "qa" },
+			wantErr: "ENVIRONMENT must be",
+		},
+		{
+			name:    "port out of range",
+			change:  func(cfg *Config) { cfg.Port = "70000" },
+			wantErr: "PORT must be a port number",
+		},
+		{
+			name:    "development key encryption key",
+			change:  func(cfg *Config) { cfg.JWT.KeyEncryptionKey = developmentKeyEncryptionKey },
+			wantErr: "JWT_KEY_ENCRYPTION_KEY is set to the development key",
+		},
+		{
+			name:    "short key encryption key",
+			change:  func(cfg *Config) { cfg.JWT.KeyEncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 16)) },
+			wantErr: "JWT_KEY_ENCRYPTION_KEY must be 32 random bytes",
+		},
+		{
+			name:    "key retention shorter than refresh tokens",
+			change:  func(cfg *Config) { cfg.JWT.Retention = 3 * 24 * time.Hour },
+			wantErr: "JWT_KEY_RETENTION must be at least the refresh token lifetime",
+		},
+		{
+			name: "key retention ignored with HS256",
+			change: func(cfg *Config) {
+				cfg.JWT.Algorithm = "HS256"
+				cfg.JWT.Retention = 0
+			},
+		},
+		{
+			name: "smtp notifier",
+			change: func(cfg *Config) {
+				cfg.Notifier.Driver = "smtp"
+				cfg.Notifier.SMTPHost = "smtp.example.com"
+				cfg.Reset.URL = "https://app.example.com/reset-password"
+			},
+		},
+		{
+			name:    "unknown notifier driver",
+			change:  func(cfg *Config) { cfg.Notifier.Driver = "sendgrid" },
+			wantErr: "NOTIFIER_DRIVER must be smtp, log or none",
+		},
+		{
+			name: "smtp notifier without a host",
+			change: func(cfg *Config) {
+				cfg.Notifier.Driver = "smtp"
+				cfg.Reset.URL = "https://app.example.com/reset-password"
+			},
+			wantErr: "SMTP_HOST is required",
+		},
+		{
+			name: "notifier without a reset page",
+			change: func(cfg *Config) {
+				cfg.Notifier.Driver = "smtp"
+				cfg.Notifier.SMTPHost = "smtp.example.com"
+			},
+			wantErr: "PASSWORD_RESET_URL must be",
+		},
+		{
+			name: "relative reset page",
+			change: func(cfg *Config) {
+				cfg.Notifier.Driver = "smtp"
+				cfg.Notifier.SMTPHost = "smtp.example.com"
+				cfg.Reset.URL = "/reset-password"
+			},
+			wantErr: "PASSWORD_RESET_URL must be",
+		},
+		{
+			name: "log notifier in production",
+			change: func(cfg *Config) {
+				cfg.Notifier.Driver = "log"
+				cfg.Reset.URL = "https://app.example.com/reset-password"
+			},
+			wantErr: "can't be log in production",
+		},
+		{
+			name:    "log SMS driver in production",
+			change:  func(cfg *Config) { cfg.Notifier.SMSDriver = "log" },
+			wantErr: "can't be log in production",
+		},
+		{
+			name: "log drivers in staging",
+			change: func(cfg *Config) {
+				cfg.Environment = "staging"
+				cfg.Notifier.Driver = "log"
+				cfg.Notifier.SMSDriver = "log"
+				cfg.Reset.URL = "https://staging.example.com/reset-password"
+			},
+		},
+		{
+			name:    "webhook SMS driver without a URL",
+			change:  func(cfg *Config) { cfg.Notifier.SMSDriver = "webhook" },
+			wantErr: "SMS_WEBHOOK_URL is required",
+		},
+		{
+			name:    "phone verification without an SMS driver",
+			change:  func(cfg *Config) { cfg.Register.Verification = "phone" },
+			wantErr: "REGISTRATION_VERIFICATION=phone requires an SMS_DRIVER",
+		},
+		{
+			name:    "email verification without a notifier",
+			change:  func(cfg *Config) { cfg.Register.Verification = "email" },
+			wantErr: "REGISTRATION_VERIFICATION=email requires a NOTIFIER_DRIVER",
+		},
+		{
+			name:    "unknown registration mode",
+			change:  func(cfg *Config) { cfg.Register.Mode = "public" },
+			wantErr: "REGISTRATION_MODE must be",
+		},
+		{
+			name:    "unknown login attempt store",
+			change:  func(cfg *Config) { cfg.Lockout.Store = "redis" },
+			wantErr: "LOGIN_ATTEMPT_STORE must be memory or postgres",
+		},
+		{
+			name:    "no lockout threshold",
+			change:  func(cfg *Config) { cfg.Lockout.IPMaxAttempts = 0 },
+			wantErr: "LOGIN_IP_MAX_ATTEMPTS must be at least 1",
+		},
+		{
+			name:    "no lockout window",
+			change:  func(cfg *Config) { cfg.Lockout.Window = 0 },
+			wantErr: "LOGIN_ATTEMPT_WINDOW, LOGIN_LOCKOUT_BASE and LOGIN_LOCKOUT_MAX must be positive",
+		},
+		{
+			name:    "maximum lockout below the first",
+			change:  func(cfg *Config) { cfg.Lockout.MaxLockout = cfg.Lockout.BaseLockout / 2 },
+			wantErr: "LOGIN_LOCKOUT_MAX must be at least LOGIN_LOCKOUT_BASE",
+		},
+		{
+			name:    "unknown rate limit store",
+			change:  func(cfg *Config) { cfg.RateLimit.Store = "postgres" },
+			wantErr: "RATE_LIMIT_STORE must be memory or redis",
+		},
+		{
+			name: "redis rate limit store without a URL",
+			change: func(cfg *Config) {
+				cfg.RateLimit.Store = "redis"
+				cfg.RateLimit.RedisURL = ""
+			},
+			wantErr: "RATE_LIMIT_REDIS_URL is required",
+		},
+		{
+			name:    "no rate limit burst",
+			change:  func(cfg *Config) { cfg.RateLimit.Write.Burst = 0 },
+			wantErr: "RATE_LIMIT_WRITE_PER_MINUTE and RATE_LIMIT_WRITE_BURST must be at least 1",
+		},
+		{
+			name:    "negative rate",
+			change:  func(cfg *Config) { cfg.RateLimit.Auth.RequestsPerMinute = -1 },
+			wantErr: "RATE_LIMIT_AUTH_PER_MINUTE",
+		},
+		{
+			name:    "idle connections above the pool size",
+			change:  func(cfg *Config) { cfg.Database.MaxIdleConns = cfg.Database.MaxOpenConns + 1 },
+			wantErr: "DB_MAX_IDLE_CONNS must be between",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validProduction()
+			tt.change(cfg)
+			err := cfg.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	cfg := validProduction()
+	cfg.Port = "http"
+	cfg.Notifier.Driver = "sendgrid"
+	cfg.Register.Mode = "public"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil")
+	}
+	for _, want := range []string{"PORT", "NOTIFIER_DRIVER", "REGISTRATION_MODE"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, missing %s", err, want)
+		}
+	}
+}
+
+func TestValidOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://app.example.com", want: true},
+		{origin: "https://*.example.com", want: true},
+		{origin: "http://localhost:*", want: true},
+		{origin: "http://localhost:3000", want: true},
+		{origin: "http://[::1]:8080", want: true},
+		{origin: "https://*.example.com:*", want: true},
+		{origin: "https://*"},
+		{origin: "https://*:443"},
+		{origin: "https://*."},
+		{origin: "https://*.*.example.com"},
+		{origin: "https://app.*.example.com"},
+		{origin: "https://*example.com"},
+		{origin: "https://app..example.com"},
+		{origin: "http://localhost:http"},
+		{origin: "https://app.example.com/"},
+		{origin: "https://user@app.example.com"},
+		{origin: "ftp://app.example.com"},
+		{origin: "app.example.com"},
+	}
+	for _, tt := range tests {
+		if got := validOrigin(tt.origin); got != tt.want {
+			t.Errorf("validOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from the defaults for the environment, the YAML or TOML
+// file at path (skipped when empty) and environment variables, in increasing precedence.
+// ENVIRONMENT is resolved first since it selects the defaults. Every malformed or unknown
+// setting is reported, not just the first.
+func Load(path string) (*Config, error) {
+	var values map[string]any
+	if path != "" {
+		var err error
+		if values, err = readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	environment := "production"
+	if env, ok := values["environment"].(string); ok {
+		environment = env
+	}
+	env, ok, err := lookupEnv("ENVIRONMENT")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		environment = env
+	}
+
+	cfg := Default(environment)
+	var errs []error
+	if values != nil {
+		errs = append(errs, applyFile(reflect.ValueOf(cfg).Elem(), values, "")...)
+	}
+	errs = append(errs, applyEnv(reflect.ValueOf(cfg).Elem(), "")...)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return values, nil
+}
+
+var (
+	durationType     = reflect.TypeOf(time.Duration(0))
+	roleMappingsType = reflect.TypeOf([]OIDCRoleMapping(nil))
+)
+
+// isSection reports whether a field groups other settings
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct
+}
+
+// applyFile sets the fields of the struct v from a decoded file section; prefix is the
+// section's dotted path for error messages
+func applyFile(v reflect.Value, values map[string]any, prefix string) []error {
+	var errs []error
+	known := make(map[string]bool)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("yaml")
+		path := prefix + key
+		known[key] = true
+
+		raw, ok := values[key]
+		if field.Tag.Get("secret") == "true" {
+			known[key+"_file"] = true
+			if file, hasFile := values[key+"_file"]; hasFile {
+				if ok {
+					errs = append(errs, fmt.Errorf("%s and %s_file are both set", path, path))
+					continue
+				}
+				name, isString := file.(string)
+				if !isString {
+					errs = append(errs, fmt.Errorf("%s_file must be a path", path))
+					continue
+				}
+				secret, err := readSecret(name)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s_file: %w", path, err))
+					continue
+				}
+				raw, ok = secret, true
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if isSection(field.Type) {
+			section, isMap := raw.(map[string]any)
+			if !isMap {
+				errs = append(errs, fmt.Errorf("%s must be a section", path))
+				continue
+			}
+			errs = append(errs, applyFile(v.Field(i), section, path+".")...)
+			continue
+		}
+		if err := setValue(v.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s%s: unknown setting", prefix, key))
+		}
+	}
+	return errs
+}
+
+// applyEnv sets the fields of the struct v from environment variables; prefix is prepended
+// to the variable names of nested fields
+func applyEnv(v reflect.Value, prefix string) []error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("env")
+
+		if isSection(field.Type) {
+			nested := prefix
+			if name != "" {
+				nested = prefix + name + "_"
+			}
+			errs = append(errs, applyEnv(v.Field(i), nested)...)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+
+		name = prefix + name
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := setString(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// lookupEnv reads the variable name, or the file named by <name>_FILE; empty values count
+// as unset
+func lookupEnv(name string) (string, bool, error) {
+	value := os.Getenv(name)
+	file := os.Getenv(name + "_FILE")
+	if file == "" {
+		return value, value != "", nil
+	}
+	if value != "" {
+		return "", false, fmt.Errorf("%s and %s_FILE are both set", name, name)
+	}
+
+	secret, err := readSecret(file)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return secret, true, nil
+}
+
+// readSecret reads a secret file, dropping the trailing newline editors and echo add
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// setString parses an environment variable (or a string in a file) into field
+func setString(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration like 30s or 5m, got %q", value)
+		}
+		field.SetInt(int64(d))
+	case field.Type() == roleMappingsType:
+		field.Set(reflect.ValueOf(parseRoleMappings(splitList(value))))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", value)
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Slice:
+		field.Set(reflect.ValueOf(splitList(value)))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// setValue stores a value decoded from a YAML or TOML file in field
+func setValue(field reflect.Value, raw any) error {
+	if value, ok := raw.(string); ok {
+		return setString(field, value)
+	}
+
+	switch {
+	case field.Type() == durationType:
+		return errors.New("must be a duration like \"30s\" or \"5m\"")
+	case field.Kind() == reflect.String:
+		// Unquoted ports decode as numbers
+		if n, ok := toInt(raw); ok {
+			field.SetString(strconv.FormatInt(n, 10))
+			return nil
+		}
+		return errors.New("must be a string")
+	case field.Kind() == reflect.Int:
+		n, ok := toInt(raw)
+		if !ok {
+			return errors.New("must be a whole number")
+		}
+		field.SetInt(n)
+	case field.Kind() == reflect.Float64:
+		if n, ok := toInt(raw); ok {
+			field.SetFloat(float64(n))
+		} else if f, ok := raw.(float64); ok {
+			field.SetFloat(f)
+		} else {
+			return errors.New("must be a number")
+		}
+	case field.Kind() == reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return errors.New("must be true or false")
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Slice:
+		items, ok := raw.([]any)
+		if !ok {
+			return errors.New("must be a list")
+		}
+		return setList(field, items)
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// setList stores a list of strings, or of role mappings written as {group, role} or "group=role"
+func setList(field reflect.Value, items []any) error {
+	if field.Type() == roleMappingsType {
+		var mappings []OIDCRoleMapping
+		for _, item := range items {
+			switch item := item.(type) {
+			case string:
+				mappings = append(mappings, parseRoleMappings([]string{item})...)
+			case map[string]any:
+				group, _ := item["group"].(string)
+				role, _ := item["role"].(string)
+				mappings = append(mappings, OIDCRoleMapping{Group: group, Role: role})
+			default:
+				return errors.New("entries must have a group and a role")
+			}
+		}
+		field.Set(reflect.ValueOf(mappings))
+		return nil
+	}
+
+	var list []string
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return errors.New("must be a list of strings")
+		}
+		list = append(list, value)
+	}
+	field.Set(reflect.ValueOf(list))
+	return nil
+}
+
+func toInt(raw any) (int64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// splitList reads a comma-separated list; an explicitly empty value ("-") yields no entries
+func splitList(value string) []string {
+	if value == "-" {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseRoleMappings reads group=role pairs, keeping their order; malformed pairs are kept
+// empty so Validate can report them
+func parseRoleMappings(entries []string) []OIDCRoleMapping {
+	var mappings []OIDCRoleMapping
+	for _, entry := range entries {
+		group, role, _ := strings.Cut(entry, "=")
+		mappings = append(mappings, OIDCRoleMapping{Group: strings.TrimSpace(group), Role: strings.TrimSpace(role)})
+	}
+	return mappings
+}
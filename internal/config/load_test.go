@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to name in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadEnvironmentSelectsDefaults(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Notifier.Driver != "log" || cfg.Register.Mode != "open" {
+		t.Errorf("development defaults: notifier %q, registration %q", cfg.Notifier.Driver, cfg.Register.Mode)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Notifier.Driver != "none" || cfg.Register.Mode != "closed" {
+		t.Errorf("production defaults: notifier %q, registration %q", cfg.Notifier.Driver, cfg.Register.Mode)
+	}
+}
+
+func TestLoadFileThenEnvironment(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+environment: staging
+port: 9000
+database:
+  max_open_conns: 40
+  connect_timeout: 5s
+notifier:
+  driver: smtp
+  smtp_host: smtp.example.com
+cors:
+  allowed_origins:
+    - https://app.example.com
+`)
+	t.Setenv("PORT", "9100")
+	t.Setenv("DB_MAX_OPEN_CONNS", "")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != "staging" {
+		t.Errorf("Environment = %q, want the file's staging", cfg.Environment)
+	}
+	if cfg.Port != "9100" {
+		t.Errorf("Port = %q, want PORT to override the file", cfg.Port)
+	}
+	if cfg.Database.MaxOpenConns != 40 || cfg.Database.ConnectTimeout != 5*time.Second {
+		t.Errorf("database = %d, %v, want the file's values (empty variables count as unset)", cfg.Database.MaxOpenConns, cfg.Database.ConnectTimeout)
+	}
+	if cfg.Notifier.Driver != "smtp" || cfg.Notifier.SMTPHost != "smtp.example.com" {
+		t.Errorf("notifier = %q, %q", cfg.Notifier.Driver, cfg.Notifier.SMTPHost)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 1 || cfg.CORS.AllowedOrigins[0] != "https://app.example.com" {
+		t.Errorf("AllowedOrigins = %v", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+environment = "test"
+port = 9000
+
+[rate_limit]
+enabled = false
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != "test" || cfg.Port != "9000" || cfg.RateLimit.Enabled {
+		t.Errorf("Load() = environment %q, port %q, rate limit %v", cfg.Environment, cfg.Port, cfg.RateLimit.Enabled)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	secret := writeFile(t, "jwt_secret", "from-a-file\n")
+	dbPassword := writeFile(t, "db_password", "db-from-a-file\n")
+	path := writeFile(t, "config.yaml", "database:\n  password_file: "+dbPassword+"\n")
+	t.Setenv("JWT_SECRET_FILE", secret)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTSecret != "from-a-file" {
+		t.Errorf("JWTSecret = %q, want the file's contents without the newline", cfg.JWTSecret)
+	}
+	if cfg.Database.Password != "db-from-a-file" {
+		t.Errorf("Database.Password = %q, want the file's contents without the newline", cfg.Database.Password)
+	}
+
+	t.Setenv("JWT_SECRET", "inline")
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "JWT_SECRET and JWT_SECRET_FILE are both set") {
+		t.Errorf("Load() with a secret and its file = %v", err)
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+port: 8080
+colour: blue
+database:
+  connect_timeout: soon
+lockout:
+  max_attempts: many
+`)
+	t.Setenv("RATE_LIMIT_ENABLED", "sometimes")
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Load() = nil")
+	}
+	for _, want := range []string{"colour: unknown setting", "database.connect_timeout", "lockout.max_attempts", "RATE_LIMIT_ENABLED"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() = %v, missing %s", err, want)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFileType(t *testing.T) {
+	path := writeFile(t, "config.json", "{}")
+	if _, err := Load(path); err == nil {
+		t.Error("Load() accepted a .json file")
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"tiger-fasttrack-card/internal/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes cfg as a YAML config file. With redact, secrets are replaced and
+// credentials embedded in other values (e.g. URLs) are masked.
+func Print(w io.Writer, cfg *Config, redact bool) error {
+	out := *cfg
+	if redact {
+		redactSecrets(reflect.ValueOf(&out).Elem())
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&out); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// redactSecrets masks the string settings of the struct v in place; unset secrets stay
+// empty so it's visible they're missing
+func redactSecrets(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		switch {
+		case isSection(t.Field(i).Type):
+			redactSecrets(field)
+		case field.Kind() == reflect.String && field.String() != "":
+			if t.Field(i).Tag.Get("secret") == "true" {
+				field.SetString(logging.Redacted)
+			} else {
+				field.SetString(logging.Redact(field.String()))
+			}
+		}
+	}
+}
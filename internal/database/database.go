@@ -1,13 +1,19 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
 	"strings"
 	"tiger-fasttrack-card/internal/config"
+	"tiger-fasttrack-card/internal/logging"
+	"tiger-fasttrack-card/internal/tracing"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 type Database struct {
@@ -17,16 +23,10 @@ type Database struct {
 func New(cfg *config.Config) (*Database, error) {
 	var dsn string
 	
-	// Debug: print configuration values
-	fmt.Printf("DEBUG: DatabaseURL: '%s'\n", cfg.DatabaseURL)
-	fmt.Printf("DEBUG: DB_HOST: '%s'\n", cfg.Database.Host)
-	fmt.Printf("DEBUG: DB_USER: '%s'\n", cfg.Database.User)
-	fmt.Printf("DEBUG: DB_NAME: '%s'\n", cfg.Database.Name)
-	
 	// Use DATABASE_URL only if it's a proper PostgreSQL URL
 	if cfg.DatabaseURL != "" && (strings.HasPrefix(cfg.DatabaseURL, "postgres://") || strings.HasPrefix(cfg.DatabaseURL, "postgresql://")) {
 		dsn = cfg.DatabaseURL
-		fmt.Printf("DEBUG: Using DATABASE_URL\n")
+		slog.Info("Using DATABASE_URL", "url", logging.Redact(cfg.DatabaseURL))
 	} else {
 		// Build DSN from individual components
 		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
@@ -37,15 +37,28 @@ func New(cfg *config.Config) (*Database, error) {
 			cfg.Database.Port,
 			cfg.Database.SSLMode,
 		)
-		fmt.Printf("DEBUG: Using individual components. DSN: %s\n", dsn)
+		slog.Info("Using database settings", "host", cfg.Database.Host, "port", cfg.Database.Port,
+			"user", cfg.Database.User, "name", cfg.Database.Name, "sslmode", cfg.Database.SSLMode)
+	}
+	dsn, err := withTimeouts(dsn, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	gormLogger, err := logging.NewGormLogger(slog.Default(), cfg.Logging.DatabaseLevel, cfg.Logging.SlowQueryThreshold)
+	if err != nil {
+		return nil, err
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: gormLogger,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
 
 	// Get underlying sql.DB to configure connection pool
 	sqlDB, err := db.DB()
@@ -54,12 +67,47 @@ func New(cfg *config.Config) (*Database, error) {
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 
 	return &Database{DB: db}, nil
 }
 
+// withTimeouts adds the connect and statement timeouts to a URL or key=value DSN, unless
+// the DSN already sets them
+func withTimeouts(dsn string, cfg config.DatabaseConfig) (string, error) {
+	params := map[string]string{
+		"connect_timeout": strconv.Itoa(int(cfg.ConnectTimeout / time.Second)),
+	}
+	if cfg.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", errors.New("DATABASE_URL is not a valid URL")
+		}
+		query := u.Query()
+		for key, value := range params {
+			if !query.Has(key) {
+				query.Set(key, value)
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	for _, key := range []string{"connect_timeout", "statement_timeout"} {
+		if value, ok := params[key]; ok && !strings.Contains(dsn, key+"=") {
+			dsn += " " + key + "=" + value
+		}
+	}
+	return dsn, nil
+}
+
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
 	if err != nil {
@@ -1,53 +1,129 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+
+	"tiger-fasttrack-card/internal/captcha"
+	"tiger-fasttrack-card/internal/health"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/lockout"
+	"tiger-fasttrack-card/internal/middleware"
 	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/oidc"
 	"tiger-fasttrack-card/internal/service"
+	"tiger-fasttrack-card/internal/version"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	Service *service.Service
+	Health  *health.Checker
 }
 
-func New(svc *service.Service) *Handler {
+func New(svc *service.Service, checker *health.Checker) *Handler {
 	return &Handler{
 		Service: svc,
+		Health:  checker,
 	}
 }
 
-// Health check handler
-func (h *Handler) HealthCheck(c *gin.Context) {
+// Liveness probe - the process is serving requests; dependencies aren't checked so a
+// database outage doesn't get every instance restarted
+func (h *Handler) Livez(c *gin.Context) {
+	build := version.Get()
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "ok",
-		"message":   "Tiger FastTrack Card API is running",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"status":  health.StatusOK,
+		"version": build.Version,
+		"commit":  build.Commit,
+	})
+}
+
+// Readiness probe - the database is reachable and migrated, and the server isn't shutting down
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.Health.Ready(c.Request.Context())
+	build := version.Get()
+	c.JSON(readinessStatus(report), gin.H{
+		"status":  readinessText(report),
+		"checks":  report.Checks,
+		"version": build.Version,
+		"commit":  build.Commit,
+	})
+}
+
+// Health check handler - the readiness probe with a message, kept for existing monitors
+func (h *Handler) HealthCheck(c *gin.Context) {
+	report := h.Health.Ready(c.Request.Context())
+	build := version.Get()
+	c.JSON(readinessStatus(report), gin.H{
+		"status":     readinessText(report),
+		"message":    translate(c, "api_running"),
+		"checks":     report.Checks,
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+		"version":    build.Version,
+		"commit":     build.Commit,
+		"build_time": build.BuildTime,
 	})
 }
 
+func readinessStatus(report health.Report) int {
+	if report.Ready {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+func readinessText(report health.Report) string {
+	if report.Ready {
+		return health.StatusOK
+	}
+	return health.StatusFailing
+}
+
+// JWKS handler - public keys for verifying tokens issued by this API
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.Service.JWKS())
+}
+
 // Example handlers for different endpoints
 
 // GetCards handler
 func (h *Handler) GetCards(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	cards, err := h.Service.GetAllCards(userID.(uint))
+	// Optional filters: category, tier and, for admins, active=true|false
+	filter := models.CardFilter{
+		Category: c.Query("category"),
+		Tier:     c.Query("tier"),
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_active_filter"))
+			return
+		}
+		filter.IsActive = &active
+	}
+
+	cards, err := h.Service.GetAllCards(c.Request.Context(), userID.(uint), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorJSON(c, err))
 		return
 	}
+	localizeCards(c, cards)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Cards retrieved successfully",
+		"message": translate(c, "cards_retrieved"),
 		"data":    cards,
 	})
 }
@@ -56,25 +132,26 @@ func (h *Handler) GetCards(c *gin.Context) {
 func (h *Handler) GetCardByID(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid card ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
 		return
 	}
 
-	card, err := h.Service.GetCardByID(userID.(uint), uint(id))
+	card, err := h.Service.GetCardByID(c.Request.Context(), userID.(uint), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
 		return
 	}
+	card.Localize(middleware.Lang(c))
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card retrieved successfully",
+		"message": translate(c, "card_retrieved"),
 		"data":    card,
 	})
 }
@@ -83,24 +160,29 @@ func (h *Handler) GetCardByID(c *gin.Context) {
 func (h *Handler) CreateCard(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	var req models.CreateCardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	card, err := h.Service.CreateCard(userID.(uint), &req)
+	card, err := h.Service.CreateCard(c.Request.Context(), userID.(uint), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrInsufficientPermissions) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
+	card.Localize(middleware.Lang(c))
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Card created successfully",
+		"message": translate(c, "card_created"),
 		"data":    card,
 	})
 }
@@ -109,31 +191,36 @@ func (h *Handler) CreateCard(c *gin.Context) {
 func (h *Handler) UpdateCard(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid card ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
 		return
 	}
 
 	var req models.UpdateCardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	card, err := h.Service.UpdateCard(userID.(uint), uint(id), &req)
+	card, err := h.Service.UpdateCard(c.Request.Context(), userID.(uint), uint(id), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrInsufficientPermissions) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
+	card.Localize(middleware.Lang(c))
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card updated successfully",
+		"message": translate(c, "card_updated"),
 		"data":    card,
 	})
 }
@@ -142,25 +229,133 @@ func (h *Handler) UpdateCard(c *gin.Context) {
 func (h *Handler) DeleteCard(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid card ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+
+	err = h.Service.DeleteCard(c.Request.Context(), userID.(uint), uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrInsufficientPermissions) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "card_deleted"),
+	})
+}
+
+// CreateCardNumberRange handler - pre-generates card numbers for printed stock (admin only)
+func (h *Handler) CreateCardNumberRange(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+
+	var req models.CreateCardNumberRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	rng, err := h.Service.CreateCardNumberRange(c.Request.Context(), userID.(uint), uint(cardID), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInsufficientPermissions) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": translate(c, "card_number_range_created"),
+		"data":    rng,
+	})
+}
+
+// GetCardNumberRanges handler - lists a card's pre-generated ranges (admin only)
+func (h *Handler) GetCardNumberRanges(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+
+	ranges, err := h.Service.GetCardNumberRanges(c.Request.Context(), userID.(uint), uint(cardID))
+	if err != nil {
+		c.JSON(http.StatusForbidden, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "card_number_ranges_retrieved"),
+		"data":    ranges,
+	})
+}
+
+// GetCardNumberRange handler - a pre-generated range with its numbers (admin only)
+// format=csv returns the numbers one per line for the card printer
+func (h *Handler) GetCardNumberRange(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+	rangeID, err := strconv.ParseUint(c.Param("rangeId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_number_range_id"))
 		return
 	}
 
-	err = h.Service.DeleteCard(userID.(uint), uint(id))
+	rng, err := h.Service.GetCardNumberRange(c.Request.Context(), userID.(uint), uint(cardID), uint(rangeID))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrInsufficientPermissions) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=card-%d-range-%d.csv", cardID, rangeID))
+		c.String(http.StatusOK, "card_number\n"+strings.Join(rng.Numbers, "\n")+"\n")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card deleted successfully",
+		"message": translate(c, "card_number_range_retrieved"),
+		"data":    rng,
 	})
 }
 
@@ -170,50 +365,177 @@ func (h *Handler) DeleteCard(c *gin.Context) {
 func (h *Handler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	user, err := h.Service.Register(&req)
+	user, err := h.Service.Register(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, service.ErrRegistrationClosed), errors.Is(err, captcha.ErrFailed), errors.Is(err, service.ErrInvalidInvitation):
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+		default:
+			c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		}
 		return
 	}
 
+	message := translate(c, "user_registered")
+	if user.PendingVerification != "" {
+		message = translate(c, "user_registered_verify", user.PendingVerification)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
+		"message": message,
 		"user":    user,
 	})
 }
 
+// VerifyAccount handler - activates a newly registered account
+func (h *Handler) VerifyAccount(c *gin.Context) {
+	var req models.VerifyAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	if err := h.Service.VerifyAccount(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "account_verified"),
+	})
+}
+
+// ResendVerification handler
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	if err := h.Service.ResendVerification(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, errorJSON(c, err))
+		return
+	}
+
+	// Same response whether or not the account exists
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "verification_resent"),
+	})
+}
+
 // Login handler
 func (h *Handler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	response, err := h.Service.Login(c.Request.Context(), &req, c.ClientIP())
+	if err != nil {
+		var locked *lockout.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			body := errorJSON(c, err)
+			body["retry_after"] = locked.RetryAfterSeconds()
+			c.JSON(http.StatusTooManyRequests, body)
+			return
+		}
+		if errors.Is(err, service.ErrLocalLoginDisabled) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusUnauthorized, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// oidcFlowCookie keeps the state, nonce and PKCE verifier across the IdP redirect
+const oidcFlowCookie = "oidc_flow"
+
+// OIDCLogin handler - redirects to the identity provider
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	flow, authURL, err := h.Service.BeginOIDCLogin()
+	if err != nil {
+		if errors.Is(err, service.ErrSSODisabled) {
+			c.JSON(http.StatusNotFound, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorJSON(c, err))
+		return
+	}
+
+	setOIDCFlowCookie(c, flow.Encode(), 600)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback handler - completes login after the identity provider redirects back
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	cookie, cookieErr := c.Cookie(oidcFlowCookie)
+	setOIDCFlowCookie(c, "", -1)
+
+	if idpError := c.Query("error"); idpError != "" {
+		body := middleware.ErrorBody(c, "sso_provider_error", idpError)
+		body["error_description"] = c.Query("error_description")
+		c.JSON(http.StatusUnauthorized, body)
+		return
+	}
+
+	if cookieErr != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "sso_session_expired"))
+		return
+	}
+	flow, err := oidc.ParseFlow(cookie)
+	if err != nil || flow.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "sso_invalid_state"))
 		return
 	}
 
-	response, err := h.Service.Login(&req)
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "sso_code_required"))
+		return
+	}
+
+	response, err := h.Service.CompleteOIDCLogin(c.Request.Context(), code, flow)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrSSODisabled) {
+			c.JSON(http.StatusNotFound, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusUnauthorized, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// setOIDCFlowCookie scopes the flow cookie to the OIDC endpoints; SameSite=Lax lets it
+// travel on the top-level redirect back from the identity provider
+func setOIDCFlowCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, value, maxAge, "/api/v1/auth/oidc", "", secure, true)
+}
+
 // RefreshToken handler
 func (h *Handler) RefreshToken(c *gin.Context) {
 	var req models.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	newToken, err := h.Service.RefreshToken(req.RefreshToken)
+	newToken, err := h.Service.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, errorJSON(c, err))
 		return
 	}
 
@@ -222,73 +544,1054 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	})
 }
 
-// GetProfile handler
-func (h *Handler) GetProfile(c *gin.Context) {
+// ForgotPassword handler - always responds the same way so accounts can't be probed
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	err := h.Service.RequestPasswordReset(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrPasswordResetUnavailable) {
+			c.JSON(http.StatusNotFound, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "password_reset_requested"),
+	})
+}
+
+// ResetPassword handler
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	err := h.Service.ResetPassword(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "password_reset"),
+	})
+}
+
+// Two-factor handlers
+
+// VerifyTwoFactor handler - second step of login
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	var req models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	response, err := h.Service.VerifyTwoFactorLogin(c.Request.Context(), &req, c.ClientIP())
+	if err != nil {
+		var locked *lockout.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			body := errorJSON(c, err)
+			body["retry_after"] = locked.RetryAfterSeconds()
+			c.JSON(http.StatusTooManyRequests, body)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ChallengeTwoFactorSetup handler - starts enrollment for a role that requires 2FA during login
+func (h *Handler) ChallengeTwoFactorSetup(c *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	setup, err := h.Service.BeginTwoFactorSetupForChallenge(c.Request.Context(), req.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "two_factor_setup_started"),
+		"data":    setup,
+	})
+}
+
+// ChallengeTwoFactorEnable handler - confirms enrollment during login and returns tokens
+func (h *Handler) ChallengeTwoFactorEnable(c *gin.Context) {
+	var req models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	result, err := h.Service.EnrollTwoFactorDuringLogin(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "two_factor_enabled"),
+		"data":    result,
+	})
+}
+
+// SetupTwoFactor handler
+func (h *Handler) SetupTwoFactor(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	user, err := h.Service.GetUserProfile(userID.(uint))
+	setup, err := h.Service.BeginTwoFactorSetup(c.Request.Context(), userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user": user,
+		"message": translate(c, "two_factor_setup_started"),
+		"data":    setup,
 	})
 }
 
-// UpdateProfile handler
-func (h *Handler) UpdateProfile(c *gin.Context) {
+// EnableTwoFactor handler
+func (h *Handler) EnableTwoFactor(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	var req models.UpdateProfileRequest
+	var req models.TwoFactorCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	user, err := h.Service.UpdateUserProfile(userID.(uint), &req)
+	codes, err := h.Service.EnableTwoFactor(c.Request.Context(), userID.(uint), req.Code)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Profile updated successfully",
-		"user":    user,
+		"message": translate(c, "two_factor_enabled"),
+		"data":    models.TwoFactorEnableResponse{RecoveryCodes: codes},
 	})
 }
 
-// ChangePassword handler
-func (h *Handler) ChangePassword(c *gin.Context) {
+// DisableTwoFactor handler
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	var req models.ChangePasswordRequest
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	err := h.Service.DisableTwoFactor(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "two_factor_disabled"),
+	})
+}
+
+// RegenerateRecoveryCodes handler
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	codes, err := h.Service.RegenerateRecoveryCodes(c.Request.Context(), userID.(uint), req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "recovery_codes_regenerated"),
+		"data":    models.TwoFactorEnableResponse{RecoveryCodes: codes},
+	})
+}
+
+// GetProfile handler
+func (h *Handler) GetProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	user, err := h.Service.GetUserProfile(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
+
+// UpdateProfile handler
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	user, err := h.Service.UpdateUserProfile(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "profile_updated"),
+		"user":    user,
+	})
+}
+
+// ChangePassword handler
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	err := h.Service.ChangePassword(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "password_changed"),
+	})
+}
+
+// UnlockUser handler (admin only) - clears a login lockout
+func (h *Handler) UnlockUser(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	targetIDStr := c.Param("id")
+	targetID, err := strconv.ParseUint(targetIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_user_id"))
+		return
+	}
+
+	err = h.Service.UnlockAccount(c.Request.Context(), userID.(uint), uint(targetID))
+	if err != nil {
+		c.JSON(http.StatusForbidden, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "account_unlocked"),
+	})
+}
+
+// Invitation handlers
+
+// CreateInvitation handler (admin only)
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	invitation, err := h.Service.CreateInvitation(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": translate(c, "invitation_created"),
+		"data":    invitation,
+	})
+}
+
+// GetInvitations handler (admin only)
+func (h *Handler) GetInvitations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	invitations, err := h.Service.ListInvitations(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusForbidden, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "invitations_retrieved"),
+		"data":    invitations,
+	})
+}
+
+// RevokeInvitation handler (admin only)
+func (h *Handler) RevokeInvitation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_invitation_id"))
+		return
+	}
+
+	err = h.Service.RevokeInvitation(c.Request.Context(), userID.(uint), uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "invitation_revoked"),
+	})
+}
+
+// API client handlers
+
+// ClientToken handler - OAuth2 client-credentials token endpoint
+// Accepts form or JSON bodies; credentials may also come from HTTP Basic auth
+func (h *Handler) ClientToken(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var req models.ClientTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	response, err := h.Service.IssueClientToken(c.Request.Context(), &req, c.ClientIP())
+	if err != nil {
+		var locked *lockout.LockedError
+		switch {
+		case errors.As(err, &locked):
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "invalid_client", "error_description": translate(c, "account_locked")})
+		case errors.Is(err, service.ErrInvalidClient):
+			c.JSON(http.StatusUnauthorized, oauthErrorJSON(c, err))
+		case errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrUnsupportedGrantType):
+			c.JSON(http.StatusBadRequest, oauthErrorJSON(c, err))
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateAPIClient handler (admin only)
+func (h *Handler) CreateAPIClient(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.CreateAPIClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	credentials, err := h.Service.CreateAPIClient(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": translate(c, "api_client_created"),
+		"data":    credentials,
+	})
+}
+
+// GetAPIClients handler (admin only)
+func (h *Handler) GetAPIClients(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	clients, err := h.Service.ListAPIClients(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusForbidden, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "api_clients_retrieved"),
+		"data":    clients,
+	})
+}
+
+// RotateAPIClientSecret handler (admin only)
+func (h *Handler) RotateAPIClientSecret(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_api_client_id"))
+		return
+	}
+
+	credentials, err := h.Service.RotateAPIClientSecret(c.Request.Context(), userID.(uint), uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "api_client_secret_rotated"),
+		"data":    credentials,
+	})
+}
+
+// RevokeAPIClient handler (admin only)
+func (h *Handler) RevokeAPIClient(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_api_client_id"))
+		return
+	}
+
+	err = h.Service.RevokeAPIClient(c.Request.Context(), userID.(uint), uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "api_client_revoked_ok"),
+	})
+}
+
+// Organization handlers
+
+// CreateOrganization handler (super admin only)
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	org, err := h.Service.CreateOrganization(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": translate(c, "organization_created"),
+		"data":    org,
+	})
+}
+
+// GetOrganizations handler (super admin only)
+func (h *Handler) GetOrganizations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	orgs, err := h.Service.ListOrganizations(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusInternalServerError), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "organizations_retrieved"),
+		"data":    orgs,
+	})
+}
+
+// UpdateOrganization handler (super admin only)
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_organization_id"))
+		return
+	}
+
+	var req models.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	org, err := h.Service.UpdateOrganization(c.Request.Context(), userID.(uint), uint(id), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "organization_updated"),
+		"data":    org,
+	})
+}
+
+// AssignUserTenant handler (admin only) - moves a user to an organization and branch
+func (h *Handler) AssignUserTenant(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_user_id"))
+		return
+	}
+
+	var req models.AssignTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	user, err := h.Service.AssignUserTenant(c.Request.Context(), userID.(uint), uint(targetID), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "user_tenant_updated"),
+		"data":    user,
+	})
+}
+
+// Stock handlers
+
+// CreateBranch handler (admin only)
+func (h *Handler) CreateBranch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.CreateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	branch, err := h.Service.CreateBranch(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": translate(c, "branch_created"),
+		"data":    branch,
+	})
+}
+
+// GetBranches handler (admin only)
+func (h *Handler) GetBranches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	branches, err := h.Service.ListBranches(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusInternalServerError), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "branches_retrieved"),
+		"data":    branches,
+	})
+}
+
+// UpdateBranch handler (admin only)
+func (h *Handler) UpdateBranch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_branch_id"))
+		return
+	}
+
+	var req models.UpdateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	branch, err := h.Service.UpdateBranch(c.Request.Context(), userID.(uint), uint(id), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "branch_updated"),
+		"data":    branch,
+	})
+}
+
+// ReceiveCardBatch handler - records a delivery of physical cards (admin only)
+func (h *Handler) ReceiveCardBatch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.ReceiveBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	batch, err := h.Service.ReceiveCardBatch(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": translate(c, "card_batch_received", batch.Quantity),
+		"data":    batch,
+	})
+}
+
+// GetCardBatches handler - lists received batches, optionally for one card_id (admin only)
+func (h *Handler) GetCardBatches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	filter, ok := stockFilter(c)
+	if !ok {
+		return
+	}
+
+	batches, err := h.Service.ListCardBatches(c.Request.Context(), userID.(uint), filter.CardID)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusInternalServerError), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "card_batches_retrieved"),
+		"data":    batches,
+	})
+}
+
+// AllocateStock handler - moves in-stock serials to a branch (admin only)
+func (h *Handler) AllocateStock(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	var req models.AllocateStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	serials, err := h.Service.AllocateStock(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "stock_allocated", len(serials)),
+		"data":    serials,
+	})
+}
+
+// GetCardSerials handler - lists a card's serials filtered by state, branch_id and limit (admin only)
+func (h *Handler) GetCardSerials(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("cardId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+
+	filter, ok := stockFilter(c)
+	if !ok {
+		return
+	}
+	filter.CardID = uint(cardID)
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_limit"))
+			return
+		}
+	}
+
+	serials, err := h.Service.ListCardSerials(c.Request.Context(), userID.(uint), filter, limit)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "card_serials_retrieved"),
+		"data":    serials,
+	})
+}
+
+// GetCardSerial handler - a serial with its stock ledger history (admin only)
+func (h *Handler) GetCardSerial(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("cardId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+
+	serial, err := h.Service.GetCardSerial(c.Request.Context(), userID.(uint), uint(cardID), c.Param("serial"))
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusNotFound), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "card_serial_retrieved"),
+		"data":    serial,
+	})
+}
+
+// UpdateCardSerialState handler - issues, damages, returns or restocks a serial (admin only)
+func (h *Handler) UpdateCardSerialState(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("cardId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+		return
+	}
+
+	var req models.UpdateSerialStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
+		return
+	}
+
+	serial, err := h.Service.UpdateCardSerialState(c.Request.Context(), userID.(uint), uint(cardID), c.Param("serial"), &req)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusBadRequest), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "card_serial_updated"),
+		"data":    serial,
+	})
+}
+
+// GetStockSummary handler - serial counts per card, branch and state (admin only)
+// Accepts the same card_id, branch_id and state filters as the serial list
+func (h *Handler) GetStockSummary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	filter, ok := stockFilter(c)
+	if !ok {
+		return
+	}
+
+	levels, err := h.Service.StockSummary(c.Request.Context(), userID.(uint), filter)
+	if err != nil {
+		c.JSON(adminErrorStatus(err, http.StatusInternalServerError), errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "stock_summary_retrieved"),
+		"data":    levels,
+	})
+}
+
+// stockFilter reads the card_id, branch_id and state query parameters; it writes the
+// error response and returns false when one is malformed
+func stockFilter(c *gin.Context) (models.StockFilter, bool) {
+	filter := models.StockFilter{State: strings.ToLower(c.Query("state"))}
+	if cardIDStr := c.Query("card_id"); cardIDStr != "" {
+		cardID, err := strconv.ParseUint(cardIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_id"))
+			return filter, false
+		}
+		filter.CardID = uint(cardID)
+	}
+	if branchIDStr := c.Query("branch_id"); branchIDStr != "" {
+		branchID, err := strconv.ParseUint(branchIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_branch_id"))
+			return filter, false
+		}
+		id := uint(branchID)
+		filter.BranchID = &id
+	}
+	return filter, true
+}
+
+// adminErrorStatus is 403 when the caller isn't an admin and status otherwise
+func adminErrorStatus(err error, status int) int {
+	if errors.Is(err, service.ErrInsufficientPermissions) {
+		return http.StatusForbidden
+	}
+	return status
+}
+
+// Person handlers
+
+// GetPeople handler (admin only)
+func (h *Handler) GetPeople(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	people, err := h.Service.ListPeople(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusForbidden, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "people_retrieved"),
+		"data":    people,
+	})
+}
+
+// SearchPersonByDocument handler - exact lookup by identity document
+// national_id is accepted as an alias of document_number for Thai national IDs
+func (h *Handler) SearchPersonByDocument(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	doc := models.IdentityDocument{
+		Type:    c.Query("document_type"),
+		Country: c.Query("issuing_country"),
+		Number:  c.DefaultQuery("document_number", c.Query("national_id")),
+	}
+
+	person, err := h.Service.GetPersonByDocument(c.Request.Context(), userID.(uint), doc)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "person_retrieved"),
+		"data":    person,
+	})
+}
+
+// GetPerson handler - person with all their card registrations
+func (h *Handler) GetPerson(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_person_id"))
+		return
+	}
+
+	person, err := h.Service.GetPerson(c.Request.Context(), userID.(uint), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "person_retrieved"),
+		"data":    person,
+	})
+}
+
+// UpdatePerson handler - updates contact details shared by all of the person's cards
+func (h *Handler) UpdatePerson(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_person_id"))
+		return
+	}
+
+	var req models.UpdatePersonRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	err := h.Service.ChangePassword(userID.(uint), &req)
+	person, err := h.Service.UpdatePerson(c.Request.Context(), userID.(uint), uint(id), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrInsufficientPermissions) || errors.Is(err, service.ErrPersonSharedWithOtherTenant) {
+			c.JSON(http.StatusForbidden, errorJSON(c, err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Password changed successfully",
+		"message": translate(c, "person_updated"),
+		"data":    person,
 	})
 }
 
@@ -298,24 +1601,24 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 func (h *Handler) RegisterCardOwner(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	var req models.RegisterOwnerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	cardOwner, err := h.Service.RegisterCardOwner(userID.(uint), &req)
+	cardOwner, err := h.Service.RegisterCardOwner(c.Request.Context(), userID.(uint), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Card owner registered successfully",
+		"message": translate(c, "card_owner_registered"),
 		"data":    cardOwner,
 	})
 }
@@ -324,24 +1627,24 @@ func (h *Handler) RegisterCardOwner(c *gin.Context) {
 func (h *Handler) RegisterMultipleCards(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	var req models.RegisterMultipleCardsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	cardOwners, err := h.Service.RegisterMultipleCards(userID.(uint), &req)
+	cardOwners, err := h.Service.RegisterMultipleCards(c.Request.Context(), userID.(uint), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Multiple cards registered successfully",
+		"message": translate(c, "multiple_cards_registered"),
 		"data":    cardOwners,
 	})
 }
@@ -350,18 +1653,19 @@ func (h *Handler) RegisterMultipleCards(c *gin.Context) {
 func (h *Handler) GetCardOwnerProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	profile, err := h.Service.GetCardOwnerProfile(userID.(uint))
+	profile, err := h.Service.GetCardOwnerProfile(c.Request.Context(), userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
 		return
 	}
+	localizeOwnerCard(c, profile)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card owner profile retrieved successfully",
+		"message": translate(c, "card_owner_profile_retrieved"),
 		"data":    profile,
 	})
 }
@@ -370,18 +1674,19 @@ func (h *Handler) GetCardOwnerProfile(c *gin.Context) {
 func (h *Handler) GetCardOwnerProfiles(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	profiles, err := h.Service.GetCardOwnerProfiles(userID.(uint))
+	profiles, err := h.Service.GetCardOwnerProfiles(c.Request.Context(), userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, errorJSON(c, err))
 		return
 	}
+	localizeOwnerCards(c, profiles)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card owner profiles retrieved successfully",
+		"message": translate(c, "card_owner_profiles_retrieved"),
 		"data":    profiles,
 	})
 }
@@ -390,18 +1695,19 @@ func (h *Handler) GetCardOwnerProfiles(c *gin.Context) {
 func (h *Handler) GetAllCardOwners(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
-	cardOwners, err := h.Service.GetAllCardOwners(userID.(uint))
+	cardOwners, err := h.Service.GetAllCardOwners(c.Request.Context(), userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		c.JSON(http.StatusForbidden, errorJSON(c, err))
 		return
 	}
+	localizeOwnerCards(c, cardOwners)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card owners retrieved successfully",
+		"message": translate(c, "card_owners_retrieved"),
 		"data":    cardOwners,
 	})
 }
@@ -410,7 +1716,7 @@ func (h *Handler) GetAllCardOwners(c *gin.Context) {
 func (h *Handler) UpdateCardOwner(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
@@ -418,24 +1724,24 @@ func (h *Handler) UpdateCardOwner(c *gin.Context) {
 	cardOwnerIDStr := c.Param("id")
 	cardOwnerID, err := strconv.ParseUint(cardOwnerIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid card owner ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_owner_id"))
 		return
 	}
 
 	var req models.UpdateCardOwnerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	cardOwner, err := h.Service.UpdateCardOwner(userID.(uint), uint(cardOwnerID), &req)
+	cardOwner, err := h.Service.UpdateCardOwner(c.Request.Context(), userID.(uint), uint(cardOwnerID), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card owner updated successfully",
+		"message": translate(c, "card_owner_updated"),
 		"data":    cardOwner,
 	})
 }
@@ -444,7 +1750,7 @@ func (h *Handler) UpdateCardOwner(c *gin.Context) {
 func (h *Handler) DeleteCardOwner(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
@@ -452,18 +1758,18 @@ func (h *Handler) DeleteCardOwner(c *gin.Context) {
 	cardOwnerIDStr := c.Param("id")
 	cardOwnerID, err := strconv.ParseUint(cardOwnerIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid card owner ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "invalid_card_owner_id"))
 		return
 	}
 
-	err = h.Service.DeleteCardOwner(userID.(uint), uint(cardOwnerID))
+	err = h.Service.DeleteCardOwner(c.Request.Context(), userID.(uint), uint(cardOwnerID))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Card owner deleted successfully",
+		"message": translate(c, "card_owner_deleted"),
 	})
 }
 
@@ -475,22 +1781,21 @@ func (h *Handler) ValidateDuplicateCardRegistration(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, bindErrorJSON(c, err))
 		return
 	}
 
-	err := h.Service.CardOwnerService.ValidateDuplicateCardRegistration(req.CardID, req.CardNumber)
+	err := h.Service.ValidateDuplicateCardRegistration(c.Request.Context(), req.CardID, req.CardNumber)
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":     "Duplicate card registration",
-			"message":   err.Error(),
-			"duplicate": true,
-		})
+		body := middleware.ErrorBody(c, "duplicate_card_registration")
+		_, body["message"], _ = i18n.Translate(middleware.Lang(c), err)
+		body["duplicate"] = true
+		c.JSON(http.StatusConflict, body)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Card registration is valid",
+		"message":   translate(c, "card_registration_valid"),
 		"duplicate": false,
 	})
 }
@@ -499,7 +1804,7 @@ func (h *Handler) ValidateDuplicateCardRegistration(c *gin.Context) {
 func (h *Handler) SearchCardOwnersByCardNameAndNumber(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
@@ -509,45 +1814,47 @@ func (h *Handler) SearchCardOwnersByCardNameAndNumber(c *gin.Context) {
 
 	// At least one parameter should be provided
 	if cardName == "" && cardNumber == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "At least one search parameter (card_name or card_number) must be provided",
-		})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "card_search_parameter_required"))
 		return
 	}
 
-	cardOwners, err := h.Service.CardOwnerService.SearchCardOwnersByCardNameAndNumber(userID.(uint), cardName, cardNumber)
+	cardOwners, err := h.Service.SearchCardOwnersByCardNameAndNumber(c.Request.Context(), userID.(uint), cardName, cardNumber)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorJSON(c, err))
 		return
 	}
+	localizeOwnerCards(c, cardOwners)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Search completed successfully",
+		"message": translate(c, "search_completed"),
 		"data":    cardOwners,
 		"count":   len(cardOwners),
 	})
 }
 
-// SearchCardOwnersByIDCardOrPhone searches for card owners by ID card or phone number
+// SearchCardOwnersByIDCardOrPhone searches for card owners by document number or phone number
 func (h *Handler) SearchCardOwnersByIDCardOrPhone(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, "user_not_authenticated"))
 		return
 	}
 
 	// Get query parameters
 	idCard := c.Query("id_card")
 	phoneNumber := c.Query("phone_number")
+	documentType := c.Query("document_type")
+	issuingCountry := c.Query("issuing_country")
 
-	cardOwners, err := h.Service.CardOwnerService.SearchCardOwnersByIDCardOrPhone(userID.(uint), idCard, phoneNumber)
+	cardOwners, err := h.Service.SearchCardOwnersByIDCardOrPhone(c.Request.Context(), userID.(uint), idCard, phoneNumber, documentType, issuingCountry)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorJSON(c, err))
 		return
 	}
+	localizeOwnerCards(c, cardOwners)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Search completed successfully",
+		"message": translate(c, "search_completed"),
 		"data":    cardOwners,
 		"count":   len(cardOwners),
 	})
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/middleware"
+	"tiger-fasttrack-card/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// translate returns the success message for code in the request's language
+func translate(c *gin.Context, code string, args ...any) string {
+	return i18n.T(middleware.Lang(c), code, args...)
+}
+
+// errorJSON is the body for an error returned by the service layer; errors missing
+// from the catalog keep their message and have no code
+func errorJSON(c *gin.Context, err error) gin.H {
+	code, message, ok := i18n.Translate(middleware.Lang(c), err)
+	if !ok {
+		return middleware.WithTraceID(c, gin.H{"error": message})
+	}
+	return middleware.WithTraceID(c, gin.H{"error": message, "code": code})
+}
+
+// oauthErrorJSON is the RFC 6749 error body for a coded error: the code is the OAuth error
+// and the translated message its description
+func oauthErrorJSON(c *gin.Context, err error) gin.H {
+	code, message, _ := i18n.Translate(middleware.Lang(c), err)
+	return gin.H{"error": code, "error_description": message}
+}
+
+// bindErrorJSON is the body for a request that failed to bind or validate; the
+// validator's message is passed on untranslated as details
+func bindErrorJSON(c *gin.Context, err error) gin.H {
+	body := middleware.ErrorBody(c, "invalid_request")
+	body["details"] = err.Error()
+	return body
+}
+
+// localizeCards fills the localized name and description of cards
+func localizeCards(c *gin.Context, cards []models.Card) {
+	lang := middleware.Lang(c)
+	for i := range cards {
+		cards[i].Localize(lang)
+	}
+}
+
+// localizeOwnerCards fills the localized name and description of the cards in card owner results
+func localizeOwnerCards(c *gin.Context, owners []models.CardOwnerWithCard) {
+	for i := range owners {
+		localizeOwnerCard(c, &owners[i])
+	}
+}
+
+func localizeOwnerCard(c *gin.Context, owner *models.CardOwnerWithCard) {
+	lang := middleware.Lang(c)
+	owner.CardOwner.Card.Localize(lang)
+	if owner.Card != nil {
+		owner.Card.Localize(lang)
+	}
+}
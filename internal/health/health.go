@@ -0,0 +1,103 @@
+// Package health backs the liveness and readiness probes. Liveness only shows the process
+// is serving; readiness runs dependency checks, each with a timeout, and fails once the
+// server starts shutting down so load balancers stop routing to it.
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check statuses reported per dependency
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusTimeout  = "timeout"
+	StatusDraining = "draining"
+)
+
+// Check reports whether a dependency is usable; it must return when ctx is done
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks
+type Checker struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// Report is the outcome of a readiness check. Errors are logged rather than returned so
+// probes don't expose hosts or credentials.
+type Report struct {
+	Ready  bool
+	Checks map[string]string // check name to status
+}
+
+// NewChecker creates a checker giving each check timeout to complete
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a readiness check
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining makes readiness fail from now on; call it when shutdown starts
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Ready runs every check concurrently
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{Ready: true, Checks: make(map[string]string, len(c.checks)+1)}
+	if c.draining.Load() {
+		report.Ready = false
+		report.Checks["shutdown"] = StatusDraining
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			status := c.run(ctx, nc)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[nc.name] = status
+			if status != StatusOK {
+				report.Ready = false
+			}
+		}(nc)
+	}
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, nc namedCheck) string {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	err := nc.check(ctx)
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil:
+		slog.WarnContext(ctx, "Readiness check timed out", "check", nc.name, "timeout", c.timeout)
+		return StatusTimeout
+	default:
+		slog.WarnContext(ctx, "Readiness check failed", "check", nc.name, "error", err)
+		return StatusFailing
+	}
+}
@@ -0,0 +1,121 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	EN = "en"
+	TH = "th"
+)
+
+// Default is used when the client accepts none of the supported languages
+const Default = EN
+
+// Supported lists the languages with translations, in fallback order after the requested one
+var Supported = []string{EN, TH}
+
+// Message holds the translations of one message, keyed by language
+// Texts may contain fmt verbs filled from the arguments given to T or NewError
+type Message map[string]string
+
+// T returns the message for code in lang, falling back to English and then to the
+// code itself when there is no translation
+func T(lang, code string, args ...any) string {
+	msg, ok := messages[code]
+	if !ok {
+		return code
+	}
+	text := msg[lang]
+	if text == "" {
+		text = msg[Default]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Error is an error with a stable code; Error() returns the English message
+type Error struct {
+	Code string
+	Args []any
+}
+
+// NewError returns an error for code; args fill the message's fmt verbs
+func NewError(code string, args ...any) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(EN, e.Code, e.Args...)
+}
+
+// Translate returns the code of the *Error in err's chain and its message in lang; ok is
+// false for other errors, whose message is returned unchanged
+func Translate(lang string, err error) (code, message string, ok bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code, T(lang, e.Code, e.Args...), true
+	}
+	return "", err.Error(), false
+}
+
+// Localize picks the value for lang from values keyed by language, falling back to the
+// other supported languages in order when it's empty
+func Localize(lang string, values map[string]string) string {
+	if v := values[lang]; v != "" {
+		return v
+	}
+	for _, l := range Supported {
+		if v := values[l]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Negotiate picks the supported language the client prefers from an Accept-Language
+// header such as "th-TH,th;q=0.9,en;q=0.8"
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if primary == "" || q <= 0 {
+			continue
+		}
+		if primary == "*" {
+			primary = Default
+		}
+		candidates = append(candidates, candidate{primary, q})
+	}
+
+	// Stable so equally weighted languages keep the client's order
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		for _, lang := range Supported {
+			if c.lang == lang {
+				return lang
+			}
+		}
+	}
+	return Default
+}
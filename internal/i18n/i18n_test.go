@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: EN},
+		{header: "th", want: TH},
+		{header: "th-TH,th;q=0.9,en;q=0.8", want: TH},
+		{header: "en-US,en;q=0.9,th;q=0.8", want: EN},
+		{header: "en;q=0.5, th;q=0.8", want: TH},
+		{header: "TH-th", want: TH},
+		{header: "fr-FR,fr;q=0.9,th;q=0.5", want: TH},
+		{header: "fr-FR,de", want: EN},
+		{header: "th;q=0, en", want: EN},
+		{header: "th;q=abc, en;q=0.1", want: EN},
+		{header: "*", want: EN},
+		{header: "ja, *;q=0.5, th;q=0.4", want: EN},
+		{header: "en, th", want: EN},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		lang        string
+		wantCode    string
+		wantMessage string
+		wantOK      bool
+	}{
+		{name: "coded", err: NewError("user_not_found"), lang: TH, wantCode: "user_not_found", wantMessage: "ไม่พบผู้ใช้", wantOK: true},
+		{name: "with arguments", err: NewError("invalid_invitation_role", "user, admin"), lang: EN, wantCode: "invalid_invitation_role", wantMessage: "role must be one of: user, admin", wantOK: true},
+		{name: "wrapped", err: fmt.Errorf("lookup: %w", NewError("user_not_found")), lang: EN, wantCode: "user_not_found", wantMessage: "user not found", wantOK: true},
+		{name: "plain error with catalog text", err: errors.New("user not found"), lang: TH, wantMessage: "user not found"},
+		{name: "unknown code", err: NewError("no_such_code"), lang: TH, wantCode: "no_such_code", wantMessage: "no_such_code", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message, ok := Translate(tt.lang, tt.err)
+			if code != tt.wantCode || message != tt.wantMessage || ok != tt.wantOK {
+				t.Errorf("Translate() = %q, %q, %v, want %q, %q, %v", code, message, ok, tt.wantCode, tt.wantMessage, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCatalogIsComplete(t *testing.T) {
+	for code, msg := range messages {
+		for _, lang := range Supported {
+			if msg[lang] == "" {
+				t.Errorf("%s has no %s text", code, lang)
+			}
+		}
+		if strings.Count(msg[EN], "%") != strings.Count(msg[TH], "%") {
+			t.Errorf("%s: the translations take different arguments", code)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package i18n
+
+// messages is the catalog of API messages keyed by code. Errors meant for API clients are
+// created with NewError, so their code travels with them.
+var messages = map[string]Message{
+	// Request and authentication errors
+	"invalid_request":              {EN: "invalid request", TH: "คำขอไม่ถูกต้อง"},
+	"internal_server_error":        {EN: "Internal server error", TH: "เกิดข้อผิดพลาดภายในระบบ"},
+	"user_not_authenticated":       {EN: "User not authenticated", TH: "ยังไม่ได้เข้าสู่ระบบ"},
+	"authorization_required":       {EN: "Authorization header required", TH: "ต้องระบุ Authorization header"},
+	"invalid_authorization_header": {EN: "Invalid authorization header format", TH: "รูปแบบ Authorization header ไม่ถูกต้อง"},
+	"invalid_token":                {EN: "Invalid token", TH: "โทเคนไม่ถูกต้อง"},
+	"api_client_not_allowed":       {EN: "Endpoint not available to API clients", TH: "API client ไม่สามารถใช้งาน endpoint นี้ได้"},
+	"insufficient_scope":           {EN: "token lacks the %s scope", TH: "โทเคนไม่มี scope %s"},
+	"too_many_requests":            {EN: "Too many requests", TH: "มีคำขอมากเกินไป กรุณาลองใหม่ภายหลัง"},
+	"request_too_large":            {EN: "Request body too large", TH: "ข้อมูลคำขอมีขนาดใหญ่เกินไป"},
+	"json_too_deep":                {EN: "Request JSON is nested too deeply", TH: "ข้อมูล JSON ในคำขอซ้อนกันลึกเกินไป"},
+	"insufficient_permissions":     {EN: "insufficient permissions", TH: "ไม่มีสิทธิ์ดำเนินการ"},
+	"invalid_card_id":              {EN: "Invalid card ID", TH: "รหัสบัตรไม่ถูกต้อง"},
+	"invalid_card_owner_id":        {EN: "Invalid card owner ID", TH: "รหัสผู้ถือบัตรไม่ถูกต้อง"},
+	"invalid_person_id":            {EN: "Invalid person ID", TH: "รหัสบุคคลไม่ถูกต้อง"},
+	"invalid_user_id":              {EN: "Invalid user ID", TH: "รหัสผู้ใช้ไม่ถูกต้อง"},
+	"invalid_invitation_id":        {EN: "Invalid invitation ID", TH: "รหัสคำเชิญไม่ถูกต้อง"},
+	"invalid_api_client_id":        {EN: "Invalid API client ID", TH: "รหัส API client ไม่ถูกต้อง"},
+	"invalid_active_filter":        {EN: "Invalid active filter", TH: "ตัวกรองสถานะการใช้งานไม่ถูกต้อง"},
+	"invalid_branch_id":            {EN: "Invalid branch ID", TH: "รหัสสาขาไม่ถูกต้อง"},
+	"invalid_limit":                {EN: "Invalid limit", TH: "จำนวนรายการที่ต้องการไม่ถูกต้อง"},
+	"invalid_organization_id":      {EN: "Invalid organization ID", TH: "รหัสองค์กรไม่ถูกต้อง"},
+	"card_search_parameter_required": {
+		EN: "At least one search parameter (card_name or card_number) must be provided",
+		TH: "ต้องระบุเงื่อนไขการค้นหาอย่างน้อยหนึ่งอย่าง (card_name หรือ card_number)",
+	},
+	"duplicate_card_registration": {EN: "Duplicate card registration", TH: "บัตรนี้ถูกลงทะเบียนแล้ว"},
+
+	// Accounts and login
+	"invalid_credentials":           {EN: "invalid username or password", TH: "ชื่อผู้ใช้หรือรหัสผ่านไม่ถูกต้อง"},
+	"account_deactivated":           {EN: "account is deactivated", TH: "บัญชีถูกระงับการใช้งาน"},
+	"account_pending_verification":  {EN: "account is pending %s verification", TH: "บัญชีกำลังรอการยืนยันทาง %s"},
+	"account_not_authorized":        {EN: "account is not authorized for this application", TH: "บัญชีนี้ไม่ได้รับอนุญาตให้ใช้งานแอปพลิเคชันนี้"},
+	"account_locked":                {EN: "too many failed login attempts, please try again later", TH: "เข้าสู่ระบบไม่สำเร็จหลายครั้งเกินไป กรุณาลองใหม่ภายหลัง"},
+	"local_login_disabled":          {EN: "password login is disabled for this account, use single sign-on", TH: "บัญชีนี้ไม่สามารถเข้าสู่ระบบด้วยรหัสผ่านได้ กรุณาใช้ Single Sign-On"},
+	"user_not_found":                {EN: "user not found", TH: "ไม่พบผู้ใช้"},
+	"username_taken":                {EN: "username is already taken", TH: "ชื่อผู้ใช้นี้ถูกใช้แล้ว"},
+	"username_in_use":               {EN: "username is already used by another account", TH: "ชื่อผู้ใช้นี้ถูกใช้โดยบัญชีอื่นแล้ว"},
+	"email_registered":              {EN: "email is already registered", TH: "อีเมลนี้ถูกลงทะเบียนแล้ว"},
+	"email_required":                {EN: "email is required", TH: "ต้องระบุอีเมล"},
+	"phone_required":                {EN: "phone is required", TH: "ต้องระบุหมายเลขโทรศัพท์"},
+	"username_or_email_required":    {EN: "username or email is required", TH: "ต้องระบุชื่อผู้ใช้หรืออีเมล"},
+	"invalid_refresh_token":         {EN: "invalid refresh token", TH: "refresh token ไม่ถูกต้อง"},
+	"refresh_token_revoked":         {EN: "refresh token has been revoked", TH: "refresh token ถูกเพิกถอนแล้ว"},
+	"registration_closed":           {EN: "registration is closed", TH: "ปิดรับการลงทะเบียน"},
+	"captcha_failed":                {EN: "captcha verification failed", TH: "การยืนยัน CAPTCHA ไม่สำเร็จ"},
+	"invalid_invitation":            {EN: "invalid or expired invitation code", TH: "รหัสคำเชิญไม่ถูกต้องหรือหมดอายุ"},
+	"invitation_code_required":      {EN: "invitation code is required", TH: "ต้องระบุรหัสคำเชิญ"},
+	"invitation_email_mismatch":     {EN: "invitation was issued for a different email address", TH: "คำเชิญนี้ออกให้กับอีเมลอื่น"},
+	"invitation_not_found":          {EN: "invitation not found", TH: "ไม่พบคำเชิญ"},
+	"invitation_used":               {EN: "invitation has already been used", TH: "คำเชิญนี้ถูกใช้แล้ว"},
+	"invitation_unavailable":        {EN: "invitation is no longer valid", TH: "คำเชิญนี้ใช้ไม่ได้แล้ว"},
+	"invalid_invitation_role":       {EN: "role must be one of: %s", TH: "บทบาทต้องเป็นหนึ่งใน: %s"},
+	"invalid_user_role":             {EN: "role must be user, admin or super_admin", TH: "บทบาทต้องเป็น user, admin หรือ super_admin"},
+	"invalid_verification_code":     {EN: "invalid or expired verification code", TH: "รหัสยืนยันไม่ถูกต้องหรือหมดอายุ"},
+	"verification_code_not_found":   {EN: "verification code not found", TH: "ไม่พบรหัสยืนยัน"},
+	"sso_disabled":                  {EN: "single sign-on is not configured", TH: "ยังไม่ได้ตั้งค่า Single Sign-On"},
+	"sso_failed":                    {EN: "single sign-on failed", TH: "Single Sign-On ไม่สำเร็จ"},
+	"sso_start_failed":              {EN: "failed to start single sign-on", TH: "ไม่สามารถเริ่ม Single Sign-On ได้"},
+	"sso_session_expired":           {EN: "login session expired, please start again", TH: "เซสชันการเข้าสู่ระบบหมดอายุ กรุณาเริ่มใหม่"},
+	"sso_invalid_state":             {EN: "invalid login state", TH: "สถานะการเข้าสู่ระบบไม่ถูกต้อง"},
+	"sso_code_required":             {EN: "authorization code is required", TH: "ต้องระบุ authorization code"},
+	"sso_provider_error":            {EN: "identity provider error: %s", TH: "ผู้ให้บริการยืนยันตัวตนแจ้งข้อผิดพลาด: %s"},
+	"failed_to_verify_login":        {EN: "failed to verify login attempts", TH: "ไม่สามารถตรวจสอบการเข้าสู่ระบบได้"},
+	"failed_to_create_user":         {EN: "failed to create user", TH: "ไม่สามารถสร้างผู้ใช้ได้"},
+	"failed_to_update_user":         {EN: "failed to update user", TH: "ไม่สามารถอัปเดตผู้ใช้ได้"},
+	"failed_to_update_profile":      {EN: "failed to update profile", TH: "ไม่สามารถอัปเดตโปรไฟล์ได้"},
+	"failed_to_unlock_account":      {EN: "failed to unlock account", TH: "ไม่สามารถปลดล็อกบัญชีได้"},
+	"failed_to_verify_account":      {EN: "failed to verify account", TH: "ไม่สามารถยืนยันบัญชีได้"},
+	"failed_to_generate_token":      {EN: "failed to generate token", TH: "ไม่สามารถสร้างโทเคนได้"},
+	"failed_to_generate_refresh":    {EN: "failed to generate refresh token", TH: "ไม่สามารถสร้าง refresh token ได้"},
+	"failed_to_generate_new_token":  {EN: "failed to generate new token", TH: "ไม่สามารถสร้างโทเคนใหม่ได้"},
+	"failed_to_create_invitation":   {EN: "failed to create invitation", TH: "ไม่สามารถสร้างคำเชิญได้"},
+	"failed_to_generate_invitation": {EN: "failed to generate invitation code", TH: "ไม่สามารถสร้างรหัสคำเชิญได้"},
+	"failed_to_revoke_invitation":   {EN: "failed to revoke invitation", TH: "ไม่สามารถเพิกถอนคำเชิญได้"},
+
+	// Passwords
+	"password_incorrect":          {EN: "password is incorrect", TH: "รหัสผ่านไม่ถูกต้อง"},
+	"current_password_incorrect":  {EN: "current password is incorrect", TH: "รหัสผ่านปัจจุบันไม่ถูกต้อง"},
+	"password_unchanged":          {EN: "new password must be different from the current password", TH: "รหัสผ่านใหม่ต้องไม่ซ้ำกับรหัสผ่านปัจจุบัน"},
+	"password_reused":             {EN: "new password must not match any of your last %d passwords", TH: "รหัสผ่านใหม่ต้องไม่ซ้ำกับรหัสผ่าน %d ครั้งล่าสุด"},
+	"password_too_short":          {EN: "password must be at least %d characters", TH: "รหัสผ่านต้องมีอย่างน้อย %d ตัวอักษร"},
+	"password_too_long":           {EN: "password must be at most %d characters", TH: "รหัสผ่านต้องมีไม่เกิน %d ตัวอักษร"},
+	"password_needs_uppercase":    {EN: "password must contain an uppercase letter", TH: "รหัสผ่านต้องมีตัวอักษรพิมพ์ใหญ่"},
+	"password_needs_lowercase":    {EN: "password must contain a lowercase letter", TH: "รหัสผ่านต้องมีตัวอักษรพิมพ์เล็ก"},
+	"password_needs_digit":        {EN: "password must contain a digit", TH: "รหัสผ่านต้องมีตัวเลข"},
+	"password_needs_symbol":       {EN: "password must contain a symbol", TH: "รหัสผ่านต้องมีอักขระพิเศษ"},
+	"password_matches_username":   {EN: "password must not contain the username", TH: "รหัสผ่านต้องไม่มีชื่อผู้ใช้อยู่ในรหัสผ่าน"},
+	"password_breached":           {EN: "password appears in a list of breached passwords, please choose another", TH: "รหัสผ่านนี้อยู่ในรายการรหัสผ่านที่รั่วไหล กรุณาเลือกรหัสผ่านอื่น"},
+	"invalid_reset_token":         {EN: "invalid or expired reset token", TH: "โทเคนรีเซ็ตรหัสผ่านไม่ถูกต้องหรือหมดอายุ"},
+	"reset_token_not_found":       {EN: "reset token not found", TH: "ไม่พบโทเคนรีเซ็ตรหัสผ่าน"},
+	"password_reset_unavailable":  {EN: "password reset is not available", TH: "ไม่สามารถใช้งานการรีเซ็ตรหัสผ่านได้"},
+	"failed_to_check_history":     {EN: "failed to check password history", TH: "ไม่สามารถตรวจสอบประวัติรหัสผ่านได้"},
+	"failed_to_hash_password":     {EN: "failed to hash password", TH: "ไม่สามารถเข้ารหัสรหัสผ่านได้"},
+	"failed_to_hash_new_password": {EN: "failed to hash new password", TH: "ไม่สามารถเข้ารหัสรหัสผ่านใหม่ได้"},
+	"failed_to_update_password":   {EN: "failed to update password", TH: "ไม่สามารถอัปเดตรหัสผ่านได้"},
+	"failed_to_reset_password":    {EN: "failed to reset password", TH: "ไม่สามารถรีเซ็ตรหัสผ่านได้"},
+	"failed_to_create_reset":      {EN: "failed to create reset token", TH: "ไม่สามารถสร้างโทเคนรีเซ็ตรหัสผ่านได้"},
+	"failed_to_generate_reset":    {EN: "failed to generate reset token", TH: "ไม่สามารถสร้างโทเคนรีเซ็ตรหัสผ่านได้"},
+
+	// Two-factor authentication
+	"invalid_two_factor_code":      {EN: "invalid two-factor code", TH: "รหัสยืนยันสองขั้นตอนไม่ถูกต้อง"},
+	"invalid_challenge_token":      {EN: "invalid or expired challenge token", TH: "challenge token ไม่ถูกต้องหรือหมดอายุ"},
+	"two_factor_already_enabled":   {EN: "two-factor authentication is already enabled", TH: "เปิดใช้การยืนยันสองขั้นตอนอยู่แล้ว"},
+	"two_factor_not_enabled":       {EN: "two-factor authentication is not enabled", TH: "ยังไม่ได้เปิดใช้การยืนยันสองขั้นตอน"},
+	"two_factor_required":          {EN: "two-factor authentication is required for your role", TH: "บทบาทของคุณต้องใช้การยืนยันสองขั้นตอน"},
+	"two_factor_setup_not_started": {EN: "two-factor setup has not been started", TH: "ยังไม่ได้เริ่มตั้งค่าการยืนยันสองขั้นตอน"},
+	"failed_to_start_two_factor":   {EN: "failed to start two-factor setup", TH: "ไม่สามารถเริ่มตั้งค่าการยืนยันสองขั้นตอนได้"},
+	"failed_to_enable_two_factor":  {EN: "failed to enable two-factor authentication", TH: "ไม่สามารถเปิดใช้การยืนยันสองขั้นตอนได้"},
+	"failed_to_disable_two_factor": {EN: "failed to disable two-factor authentication", TH: "ไม่สามารถปิดการยืนยันสองขั้นตอนได้"},
+	"failed_to_verify_two_factor":  {EN: "failed to verify two-factor code", TH: "ไม่สามารถตรวจสอบรหัสยืนยันสองขั้นตอนได้"},
+	"failed_to_generate_secret":    {EN: "failed to generate two-factor secret", TH: "ไม่สามารถสร้างรหัสลับสำหรับการยืนยันสองขั้นตอนได้"},
+	"failed_to_generate_qr":        {EN: "failed to generate QR code", TH: "ไม่สามารถสร้าง QR code ได้"},
+	"failed_to_generate_recovery":  {EN: "failed to generate recovery codes", TH: "ไม่สามารถสร้างรหัสกู้คืนได้"},
+	"failed_to_store_recovery":     {EN: "failed to store recovery codes", TH: "ไม่สามารถบันทึกรหัสกู้คืนได้"},
+	"failed_to_remove_recovery":    {EN: "failed to remove recovery codes", TH: "ไม่สามารถลบรหัสกู้คืนได้"},
+	"failed_to_verify_recovery":    {EN: "failed to verify recovery code", TH: "ไม่สามารถตรวจสอบรหัสกู้คืนได้"},
+
+	// API clients
+	"api_client_not_found":          {EN: "API client not found", TH: "ไม่พบ API client"},
+	"api_client_revoked":            {EN: "API client has been revoked", TH: "API client ถูกเพิกถอนแล้ว"},
+	"invalid_client":                {EN: "client authentication failed", TH: "การยืนยันตัวตนของ client ไม่สำเร็จ"},
+	"invalid_scope":                 {EN: "requested scope is invalid or not granted to the client", TH: "scope ที่ขอไม่ถูกต้องหรือไม่ได้รับอนุญาตสำหรับ client นี้"},
+	"unsupported_grant_type":        {EN: "only the client_credentials grant type is supported", TH: "รองรับเฉพาะ grant type แบบ client_credentials"},
+	"unknown_scope":                 {EN: "unknown scope: %s", TH: "ไม่รู้จัก scope: %s"},
+	"failed_to_create_api_client":   {EN: "failed to create API client", TH: "ไม่สามารถสร้าง API client ได้"},
+	"failed_to_revoke_api_client":   {EN: "failed to revoke API client", TH: "ไม่สามารถเพิกถอน API client ได้"},
+	"failed_to_rotate_secret":       {EN: "failed to rotate client secret", TH: "ไม่สามารถเปลี่ยน client secret ได้"},
+	"failed_to_generate_client_id":  {EN: "failed to generate client ID", TH: "ไม่สามารถสร้าง client ID ได้"},
+	"failed_to_generate_secret_key": {EN: "failed to generate client secret", TH: "ไม่สามารถสร้าง client secret ได้"},
+	"failed_to_hash_client_secret":  {EN: "failed to hash client secret", TH: "ไม่สามารถเข้ารหัส client secret ได้"},
+
+	// Cards
+	"card_not_found":                     {EN: "card not found", TH: "ไม่พบบัตร"},
+	"card_id_not_found":                  {EN: "card ID not found in master data", TH: "ไม่พบรหัสบัตรในข้อมูลหลัก"},
+	"card_not_available":                 {EN: "card is not available for registration", TH: "บัตรนี้ไม่เปิดให้ลงทะเบียน"},
+	"price_negative":                     {EN: "price cannot be negative", TH: "ราคาต้องไม่ติดลบ"},
+	"card_number_registered":             {EN: "card number %s is already registered for this card", TH: "หมายเลขบัตร %s ถูกลงทะเบียนกับบัตรนี้แล้ว"},
+	"failed_to_create_card":              {EN: "failed to create card", TH: "ไม่สามารถสร้างบัตรได้"},
+	"failed_to_update_card":              {EN: "failed to update card", TH: "ไม่สามารถอัปเดตบัตรได้"},
+	"card_owner_not_found":               {EN: "card owner not found", TH: "ไม่พบผู้ถือบัตร"},
+	"card_owner_access_denied":           {EN: "card owner not found or access denied", TH: "ไม่พบผู้ถือบัตรหรือไม่มีสิทธิ์เข้าถึง"},
+	"owner_search_required":              {EN: "at least one search parameter (ID card or phone number) must be provided", TH: "ต้องระบุเงื่อนไขการค้นหาอย่างน้อยหนึ่งอย่าง (เลขเอกสารหรือหมายเลขโทรศัพท์)"},
+	"failed_to_register_owner":           {EN: "failed to register card owner", TH: "ไม่สามารถลงทะเบียนผู้ถือบัตรได้"},
+	"failed_to_update_card_owner":        {EN: "failed to update card owner", TH: "ไม่สามารถอัปเดตผู้ถือบัตรได้"},
+	"failed_to_get_card_owners":          {EN: "failed to get card owners", TH: "ไม่สามารถดึงข้อมูลผู้ถือบัตรได้"},
+	"card_number_required":               {EN: "card number is required", TH: "ต้องระบุหมายเลขบัตร"},
+	"card_number_not_numeric":            {EN: "card number must contain only digits", TH: "หมายเลขบัตรต้องเป็นตัวเลขเท่านั้น"},
+	"card_number_length":                 {EN: "card number must be %d digits", TH: "หมายเลขบัตรต้องมี %d หลัก"},
+	"card_number_prefix":                 {EN: "card number must start with %s", TH: "หมายเลขบัตรต้องขึ้นต้นด้วย %s"},
+	"card_number_check_digit":            {EN: "card number check digit is invalid", TH: "เลขตรวจสอบของหมายเลขบัตรไม่ถูกต้อง"},
+	"card_numbers_exhausted":             {EN: "card numbers for this card are exhausted", TH: "หมายเลขบัตรของบัตรนี้ถูกใช้หมดแล้ว"},
+	"card_no_number_scheme":              {EN: "card has no numbering scheme", TH: "บัตรนี้ไม่ได้กำหนดรูปแบบหมายเลข"},
+	"invalid_number_prefix":              {EN: "number prefix must contain only digits", TH: "คำนำหน้าหมายเลขต้องเป็นตัวเลขเท่านั้น"},
+	"invalid_number_length":              {EN: "number length must leave room for the prefix and check digit", TH: "ความยาวหมายเลขต้องมากกว่าคำนำหน้าและเลขตรวจสอบ"},
+	"invalid_check_digit":                {EN: "check digit must be none or luhn", TH: "เลขตรวจสอบต้องเป็น none หรือ luhn"},
+	"invalid_range_count":                {EN: "count must be between 1 and %d", TH: "จำนวนต้องอยู่ระหว่าง 1 ถึง %d"},
+	"card_number_range_not_found":        {EN: "card number range not found", TH: "ไม่พบช่วงหมายเลขบัตร"},
+	"invalid_number_range_id":            {EN: "Invalid card number range ID", TH: "รหัสช่วงหมายเลขบัตรไม่ถูกต้อง"},
+	"failed_to_allocate_card_number":     {EN: "failed to allocate card number", TH: "ไม่สามารถออกหมายเลขบัตรได้"},
+	"failed_to_create_card_number_range": {EN: "failed to create card number range", TH: "ไม่สามารถสร้างช่วงหมายเลขบัตรได้"},
+
+	// Organizations and tenancy
+	"organization_not_found":        {EN: "organization not found", TH: "ไม่พบองค์กร"},
+	"organization_code_exists":      {EN: "organization code %s already exists", TH: "รหัสองค์กร %s มีอยู่แล้ว"},
+	"invalid_organization_type":     {EN: "organization type must be company or agency", TH: "ประเภทองค์กรต้องเป็น company หรือ agency"},
+	"organization_not_active":       {EN: "organization is not active", TH: "องค์กรนี้ไม่เปิดใช้งาน"},
+	"branch_other_organization":     {EN: "branch belongs to a different organization", TH: "สาขานี้เป็นขององค์กรอื่น"},
+	"super_admin_no_organization":   {EN: "super admins don't belong to an organization", TH: "ผู้ดูแลระบบสูงสุดไม่ได้สังกัดองค์กรใด"},
+	"failed_to_create_organization": {EN: "failed to create organization", TH: "ไม่สามารถสร้างองค์กรได้"},
+	"failed_to_update_organization": {EN: "failed to update organization", TH: "ไม่สามารถอัปเดตองค์กรได้"},
+	"serial_other_organization":     {EN: "serial %s belongs to another organization", TH: "หมายเลขซีเรียล %s เป็นขององค์กรอื่น"},
+
+	// Physical card stock
+	"branch_not_found":          {EN: "branch not found", TH: "ไม่พบสาขา"},
+	"branch_code_exists":        {EN: "branch code %s already exists", TH: "รหัสสาขา %s มีอยู่แล้ว"},
+	"branch_not_active":         {EN: "branch is not active", TH: "สาขานี้ไม่เปิดใช้งาน"},
+	"failed_to_create_branch":   {EN: "failed to create branch", TH: "ไม่สามารถสร้างสาขาได้"},
+	"failed_to_update_branch":   {EN: "failed to update branch", TH: "ไม่สามารถอัปเดตสาขาได้"},
+	"batch_serials_required":    {EN: "first_serial and last_serial or number_range_id is required", TH: "ต้องระบุ first_serial และ last_serial หรือ number_range_id"},
+	"invalid_serial_range":      {EN: "first and last serial must share a prefix and end in numbers of the same length", TH: "หมายเลขซีเรียลแรกและสุดท้ายต้องมีคำนำหน้าเดียวกันและลงท้ายด้วยตัวเลขที่มีจำนวนหลักเท่ากัน"},
+	"invalid_serial_count":      {EN: "a batch must cover between 1 and %d serials", TH: "แต่ละชุดต้องมีหมายเลขซีเรียลระหว่าง 1 ถึง %d รายการ"},
+	"serial_already_received":   {EN: "serial %s has already been received", TH: "หมายเลขซีเรียล %s ถูกรับเข้าคลังแล้ว"},
+	"serial_not_found":          {EN: "serial %s not found", TH: "ไม่พบหมายเลขซีเรียล %s"},
+	"serial_not_in_stock":       {EN: "serial %s is not in stock", TH: "หมายเลขซีเรียล %s ไม่อยู่ในคลัง"},
+	"invalid_serial_state":      {EN: "state must be in_stock, issued, damaged or returned", TH: "สถานะต้องเป็น in_stock, issued, damaged หรือ returned"},
+	"invalid_serial_transition": {EN: "serial %s cannot move from %s to %s", TH: "หมายเลขซีเรียล %s ไม่สามารถเปลี่ยนจาก %s เป็น %s ได้"},
+	"card_owner_not_issuing":    {EN: "card_owner_id can only be given when issuing a card", TH: "ระบุ card_owner_id ได้เฉพาะเมื่อออกบัตรเท่านั้น"},
+	"card_owner_other_card":     {EN: "card owner is registered for a different card", TH: "ผู้ถือบัตรลงทะเบียนไว้กับบัตรประเภทอื่น"},
+	"failed_to_receive_batch":   {EN: "failed to receive card batch", TH: "ไม่สามารถรับบัตรเข้าคลังได้"},
+	"failed_to_allocate_stock":  {EN: "failed to allocate stock", TH: "ไม่สามารถจัดสรรบัตรให้สาขาได้"},
+	"failed_to_update_serial":   {EN: "failed to update serial", TH: "ไม่สามารถอัปเดตหมายเลขซีเรียลได้"},
+
+	// People and identity documents
+	"person_not_found":         {EN: "person not found", TH: "ไม่พบบุคคล"},
+	"person_access_denied":     {EN: "person not found or access denied", TH: "ไม่พบบุคคลหรือไม่มีสิทธิ์เข้าถึง"},
+	"failed_to_get_people":     {EN: "failed to get people", TH: "ไม่สามารถดึงข้อมูลบุคคลได้"},
+	"failed_to_update_person":  {EN: "failed to update person", TH: "ไม่สามารถอัปเดตข้อมูลบุคคลได้"},
+	"person_shared":            {EN: "person also holds cards in another organization or branch", TH: "บุคคลนี้ถือบัตรขององค์กรหรือสาขาอื่นด้วย"},
+	"document_number_required": {EN: "document number is required", TH: "ต้องระบุเลขเอกสาร"},
+	"issuing_country_required": {EN: "issuing country is required for %s", TH: "ต้องระบุประเทศผู้ออกเอกสารสำหรับ %s"},
+	"invalid_issuing_country":  {EN: "issuing country must be a two-letter ISO 3166 code", TH: "ประเทศผู้ออกเอกสารต้องเป็นรหัส ISO 3166 สองตัวอักษร"},
+	"invalid_thai_national_id": {EN: "invalid Thai national ID", TH: "เลขประจำตัวประชาชนไม่ถูกต้อง"},
+	"invalid_national_id":      {EN: "national ID must be 5-20 letters or digits", TH: "เลขบัตรประจำตัวต้องเป็นตัวอักษรหรือตัวเลข 5-20 หลัก"},
+	"invalid_passport_number":  {EN: "passport number must be 6-9 letters or digits", TH: "เลขหนังสือเดินทางต้องเป็นตัวอักษรหรือตัวเลข 6-9 หลัก"},
+	"invalid_document_number":  {EN: "document number must be 3-30 letters or digits", TH: "เลขเอกสารต้องเป็นตัวอักษรหรือตัวเลข 3-30 หลัก"},
+	"invalid_document_type":    {EN: "document type must be national_id, passport or other", TH: "ประเภทเอกสารต้องเป็น national_id, passport หรือ other"},
+
+	// Success messages
+	"api_running":                   {EN: "Tiger FastTrack Card API is running", TH: "Tiger FastTrack Card API ทำงานปกติ"},
+	"cards_retrieved":               {EN: "Cards retrieved successfully", TH: "ดึงข้อมูลบัตรสำเร็จ"},
+	"card_retrieved":                {EN: "Card retrieved successfully", TH: "ดึงข้อมูลบัตรสำเร็จ"},
+	"card_created":                  {EN: "Card created successfully", TH: "สร้างบัตรสำเร็จ"},
+	"card_updated":                  {EN: "Card updated successfully", TH: "อัปเดตบัตรสำเร็จ"},
+	"card_deleted":                  {EN: "Card deleted successfully", TH: "ลบบัตรสำเร็จ"},
+	"user_registered":               {EN: "User registered successfully", TH: "ลงทะเบียนผู้ใช้สำเร็จ"},
+	"user_registered_verify":        {EN: "User registered successfully; enter the code sent to your %s to activate the account", TH: "ลงทะเบียนผู้ใช้สำเร็จ กรุณากรอกรหัสที่ส่งไปยัง %s ของคุณเพื่อเปิดใช้งานบัญชี"},
+	"account_verified":              {EN: "Account verified successfully", TH: "ยืนยันบัญชีสำเร็จ"},
+	"verification_resent":           {EN: "If the account is awaiting verification, a new code has been sent", TH: "หากบัญชีกำลังรอการยืนยัน ระบบได้ส่งรหัสใหม่ให้แล้ว"},
+	"password_reset_requested":      {EN: "If the account exists, password reset instructions have been sent", TH: "หากมีบัญชีนี้อยู่ ระบบได้ส่งวิธีรีเซ็ตรหัสผ่านให้แล้ว"},
+	"password_reset":                {EN: "Password reset successfully", TH: "รีเซ็ตรหัสผ่านสำเร็จ"},
+	"password_changed":              {EN: "Password changed successfully", TH: "เปลี่ยนรหัสผ่านสำเร็จ"},
+	"profile_updated":               {EN: "Profile updated successfully", TH: "อัปเดตโปรไฟล์สำเร็จ"},
+	"two_factor_setup_started":      {EN: "Scan the QR code with your authenticator app, then confirm with a code", TH: "สแกน QR code ด้วยแอปยืนยันตัวตน แล้วยืนยันด้วยรหัส"},
+	"two_factor_enabled":            {EN: "Two-factor authentication enabled successfully", TH: "เปิดใช้การยืนยันสองขั้นตอนสำเร็จ"},
+	"two_factor_disabled":           {EN: "Two-factor authentication disabled successfully", TH: "ปิดการยืนยันสองขั้นตอนสำเร็จ"},
+	"recovery_codes_regenerated":    {EN: "Recovery codes regenerated successfully", TH: "สร้างรหัสกู้คืนใหม่สำเร็จ"},
+	"account_unlocked":              {EN: "Account unlocked successfully", TH: "ปลดล็อกบัญชีสำเร็จ"},
+	"invitation_created":            {EN: "Invitation created successfully; the code won't be shown again", TH: "สร้างคำเชิญสำเร็จ รหัสจะไม่แสดงอีก"},
+	"invitations_retrieved":         {EN: "Invitations retrieved successfully", TH: "ดึงข้อมูลคำเชิญสำเร็จ"},
+	"invitation_revoked":            {EN: "Invitation revoked successfully", TH: "เพิกถอนคำเชิญสำเร็จ"},
+	"api_client_created":            {EN: "API client created successfully; store the client secret now, it won't be shown again", TH: "สร้าง API client สำเร็จ กรุณาเก็บ client secret ไว้ตอนนี้ เนื่องจากจะไม่แสดงอีก"},
+	"api_clients_retrieved":         {EN: "API clients retrieved successfully", TH: "ดึงข้อมูล API client สำเร็จ"},
+	"api_client_secret_rotated":     {EN: "Client secret rotated successfully; store the client secret now, it won't be shown again", TH: "เปลี่ยน client secret สำเร็จ กรุณาเก็บ client secret ไว้ตอนนี้ เนื่องจากจะไม่แสดงอีก"},
+	"api_client_revoked_ok":         {EN: "API client revoked successfully", TH: "เพิกถอน API client สำเร็จ"},
+	"card_owner_registered":         {EN: "Card owner registered successfully", TH: "ลงทะเบียนผู้ถือบัตรสำเร็จ"},
+	"multiple_cards_registered":     {EN: "Multiple cards registered successfully", TH: "ลงทะเบียนบัตรหลายใบสำเร็จ"},
+	"card_owner_profile_retrieved":  {EN: "Card owner profile retrieved successfully", TH: "ดึงข้อมูลโปรไฟล์ผู้ถือบัตรสำเร็จ"},
+	"card_owner_profiles_retrieved": {EN: "Card owner profiles retrieved successfully", TH: "ดึงข้อมูลโปรไฟล์ผู้ถือบัตรสำเร็จ"},
+	"card_owners_retrieved":         {EN: "Card owners retrieved successfully", TH: "ดึงข้อมูลผู้ถือบัตรสำเร็จ"},
+	"card_owner_updated":            {EN: "Card owner updated successfully", TH: "อัปเดตผู้ถือบัตรสำเร็จ"},
+	"card_owner_deleted":            {EN: "Card owner deleted successfully", TH: "ลบผู้ถือบัตรสำเร็จ"},
+	"card_registration_valid":       {EN: "Card registration is valid", TH: "สามารถลงทะเบียนบัตรนี้ได้"},
+	"card_number_range_created":     {EN: "Card numbers generated successfully", TH: "สร้างหมายเลขบัตรสำเร็จ"},
+	"card_number_ranges_retrieved":  {EN: "Card number ranges retrieved successfully", TH: "ดึงข้อมูลช่วงหมายเลขบัตรสำเร็จ"},
+	"card_number_range_retrieved":   {EN: "Card number range retrieved successfully", TH: "ดึงข้อมูลช่วงหมายเลขบัตรสำเร็จ"},
+	"organization_created":          {EN: "Organization created successfully", TH: "สร้างองค์กรสำเร็จ"},
+	"organizations_retrieved":       {EN: "Organizations retrieved successfully", TH: "ดึงข้อมูลองค์กรสำเร็จ"},
+	"organization_updated":          {EN: "Organization updated successfully", TH: "อัปเดตองค์กรสำเร็จ"},
+	"user_tenant_updated":           {EN: "User organization and branch updated successfully", TH: "อัปเดตองค์กรและสาขาของผู้ใช้สำเร็จ"},
+	"branch_created":                {EN: "Branch created successfully", TH: "สร้างสาขาสำเร็จ"},
+	"branches_retrieved":            {EN: "Branches retrieved successfully", TH: "ดึงข้อมูลสาขาสำเร็จ"},
+	"branch_updated":                {EN: "Branch updated successfully", TH: "อัปเดตสาขาสำเร็จ"},
+	"card_batch_received":           {EN: "%d cards received into stock", TH: "รับบัตรเข้าคลัง %d ใบ"},
+	"card_batches_retrieved":        {EN: "Card batches retrieved successfully", TH: "ดึงข้อมูลชุดบัตรสำเร็จ"},
+	"stock_allocated":               {EN: "%d cards allocated to the branch", TH: "จัดสรรบัตรให้สาขา %d ใบ"},
+	"card_serials_retrieved":        {EN: "Card serials retrieved successfully", TH: "ดึงข้อมูลหมายเลขซีเรียลสำเร็จ"},
+	"card_serial_retrieved":         {EN: "Card serial retrieved successfully", TH: "ดึงข้อมูลหมายเลขซีเรียลสำเร็จ"},
+	"card_serial_updated":           {EN: "Card serial updated successfully", TH: "อัปเดตหมายเลขซีเรียลสำเร็จ"},
+	"stock_summary_retrieved":       {EN: "Stock summary retrieved successfully", TH: "ดึงข้อมูลสรุปคลังบัตรสำเร็จ"},
+	"search_completed":              {EN: "Search completed successfully", TH: "ค้นหาสำเร็จ"},
+	"people_retrieved":              {EN: "People retrieved successfully", TH: "ดึงข้อมูลบุคคลสำเร็จ"},
+	"person_retrieved":              {EN: "Person retrieved successfully", TH: "ดึงข้อมูลบุคคลสำเร็จ"},
+	"person_updated":                {EN: "Person updated successfully", TH: "อัปเดตข้อมูลบุคคลสำเร็จ"},
+}
@@ -0,0 +1,263 @@
+package keys
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"tiger-fasttrack-card/internal/database"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// rotationLockID serializes key rotation across instances (pg_advisory_xact_lock)
+const rotationLockID = 727_031
+
+type entry struct {
+	key         *utils.SigningKey
+	activatesAt time.Time
+	expiresAt   time.Time
+}
+
+// Ring holds the keys known to this instance and implements utils.KeyProvider
+type Ring struct {
+	mu      sync.RWMutex
+	entries []entry // sorted by activatesAt, newest first
+	now     func() time.Time
+}
+
+// NewRing creates an empty key ring
+func NewRing() *Ring {
+	return &Ring{now: time.Now}
+}
+
+func (r *Ring) set(entries []entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].activatesAt.After(entries[j].activatesAt)
+	})
+
+	r.mu.Lock()
+	r.entries = entries
+	r.mu.Unlock()
+}
+
+// SigningKey returns the newest key that is already active
+func (r *Ring) SigningKey() (*utils.SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	for _, e := range r.entries {
+		if !e.activatesAt.After(now) && e.expiresAt.After(now) {
+			return e.key, nil
+		}
+	}
+	return nil, errors.New("no active signing key")
+}
+
+// VerificationKey returns an unexpired key by ID, including keys not yet active
+func (r *Ring) VerificationKey(kid string) (*utils.SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	for _, e := range r.entries {
+		if e.key.ID == kid && e.expiresAt.After(now) {
+			return e.key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// VerificationKeys returns all unexpired keys
+func (r *Ring) VerificationKeys() []*utils.SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	var keys []*utils.SigningKey
+	for _, e := range r.entries {
+		if e.expiresAt.After(now) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// RotationPolicy controls how often keys are replaced
+type RotationPolicy struct {
+	Algorithm  string        // algorithm for new keys
+	Interval   time.Duration // how long each key signs
+	Retention  time.Duration // how long a key keeps verifying after it stops signing; at least the longest token lifetime
+	Prepublish time.Duration // how long before activation a new key is published so every instance knows it
+}
+
+// Rotator keeps the signing_keys table and a Ring in sync, creating new keys on schedule
+// Private keys are stored encrypted with encryptionKey
+type Rotator struct {
+	db            *database.Database
+	ring          *Ring
+	policy        RotationPolicy
+	encryptionKey []byte
+}
+
+// NewRotator creates a rotator that loads keys into ring; encryptionKey (see
+// ParseEncryptionKey) encrypts the private keys in the database
+func NewRotator(db *database.Database, ring *Ring, policy RotationPolicy, encryptionKey []byte) *Rotator {
+	return &Rotator{
+		db:            db,
+		ring:          ring,
+		policy:        policy,
+		encryptionKey: encryptionKey,
+	}
+}
+
+// Sync creates a key if one is due and reloads all unexpired keys into the ring
+func (r *Rotator) Sync() error {
+	return r.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", rotationLockID).Error; err != nil {
+			return fmt.Errorf("failed to lock signing keys: %w", err)
+		}
+
+		now := time.Now()
+		var rows []models.SigningKey
+		if err := tx.Where("expires_at > ?", now).Order("activates_at DESC").Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to load signing keys: %w", err)
+		}
+
+		if activatesAt, due := r.nextActivation(rows, now); due {
+			row, err := r.createKey(tx, activatesAt)
+			if err != nil {
+				return err
+			}
+			slog.Info("Created JWT signing key", "kid", row.KID, "algorithm", row.Algorithm, "activates_at", row.ActivatesAt.Format(time.RFC3339))
+			rows = append(rows, *row)
+		}
+
+		entries := make([]entry, 0, len(rows))
+		for _, row := range rows {
+			key, err := r.loadKey(tx, &row)
+			if err != nil {
+				slog.Warn("Skipping unreadable signing key", "kid", row.KID, "error", err)
+				continue
+			}
+			entries = append(entries, entry{key: key, activatesAt: row.ActivatesAt, expiresAt: row.ExpiresAt})
+		}
+		r.ring.set(entries)
+		return nil
+	})
+}
+
+// loadKey decrypts a stored key; keys stored in plain text before encryption was
+// introduced are encrypted in place
+func (r *Rotator) loadKey(tx *gorm.DB, row *models.SigningKey) (*utils.SigningKey, error) {
+	if isSealed(row.PrivateKeyPEM) {
+		pem, err := open(r.encryptionKey, row.KID, row.PrivateKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		return utils.ParseSigningKeyPEM(row.KID, row.Algorithm, pem)
+	}
+
+	key, err := utils.ParseSigningKeyPEM(row.KID, row.Algorithm, row.PrivateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := seal(r.encryptionKey, row.KID, row.PrivateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+	if err := tx.Model(row).Update("private_key_pem", sealed).Error; err != nil {
+		return nil, fmt.Errorf("failed to store encrypted signing key: %w", err)
+	}
+	slog.Info("Encrypted JWT signing key", "kid", row.KID)
+	return key, nil
+}
+
+// nextActivation decides whether a new key must be created and when it activates
+// The newest key is succeeded Interval after it activated; the successor is created
+// Prepublish ahead of that. A change of algorithm triggers an early rotation.
+func (r *Rotator) nextActivation(rows []models.SigningKey, now time.Time) (time.Time, bool) {
+	if len(rows) == 0 {
+		return now, true
+	}
+
+	newest := rows[0]
+	next := newest.ActivatesAt.Add(r.policy.Interval)
+	if newest.Algorithm != r.policy.Algorithm {
+		next = now.Add(r.policy.Prepublish)
+	}
+	if now.Before(next.Add(-r.policy.Prepublish)) {
+		return time.Time{}, false
+	}
+	if earliest := now.Add(r.policy.Prepublish); next.Before(earliest) {
+		next = earliest
+	}
+	return next, true
+}
+
+func (r *Rotator) createKey(tx *gorm.DB, activatesAt time.Time) (*models.SigningKey, error) {
+	kid, err := newKeyID(activatesAt)
+	if err != nil {
+		return nil, err
+	}
+	key, err := utils.GenerateSigningKey(kid, r.policy.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	pem, err := key.PrivateKeyPEM()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	sealed, err := seal(r.encryptionKey, kid, pem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	row := &models.SigningKey{
+		KID:           kid,
+		Algorithm:     r.policy.Algorithm,
+		PrivateKeyPEM: sealed,
+		ActivatesAt:   activatesAt,
+		ExpiresAt:     activatesAt.Add(r.policy.Interval + r.policy.Retention),
+	}
+	if err := tx.Create(row).Error; err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+	return row, nil
+}
+
+// Run syncs periodically until ctx is cancelled
+// Errors are logged; the ring keeps its last known keys
+func (r *Rotator) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Sync(); err != nil {
+				slog.Error("JWT key rotation failed", "error", err)
+			}
+		}
+	}
+}
+
+// newKeyID builds a sortable, unique key ID such as "20261019-1a2b3c4d"
+func newKeyID(activatesAt time.Time) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return activatesAt.UTC().Format("20060102") + "-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,81 @@
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks a private key encrypted by seal; rows written before encryption hold
+// plain PEM, which starts with "-----BEGIN"
+const sealedPrefix = "v1:"
+
+// EncryptionKeySize is the length of the key that encrypts private keys at rest (AES-256)
+const EncryptionKeySize = 32
+
+// ParseEncryptionKey decodes a base64 encryption key, e.g. the output of `openssl rand -base64 32`
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("encryption key must be base64")
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+// seal encrypts a private key PEM with AES-GCM; the key ID is authenticated so a sealed
+// key can't be swapped onto another row
+func seal(key []byte, kid, pem string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(pem), []byte(kid))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a private key written by seal
+func open(key []byte, kid, data string) (string, error) {
+	if !isSealed(data) {
+		return "", errors.New("private key is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(data, sealedPrefix))
+	if err != nil {
+		return "", errors.New("invalid encrypted private key")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("invalid encrypted private key")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	pem, err := aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return "", errors.New("failed to decrypt private key; wrong JWT_KEY_ENCRYPTION_KEY?")
+	}
+	return string(pem), nil
+}
+
+func isSealed(data string) bool {
+	return strings.HasPrefix(data, sealedPrefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,163 @@
+package lockout
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"tiger-fasttrack-card/internal/i18n"
+)
+
+// Policy describes when a key gets locked and for how long
+type Policy struct {
+	MaxAttempts int           // failures before the first lockout; 0 disables the policy
+	Window      time.Duration // failures older than this are forgotten
+	BaseLockout time.Duration // lockout after reaching MaxAttempts
+	MaxLockout  time.Duration // upper bound for the exponential backoff
+}
+
+// lockoutFor doubles the lockout for every failure past MaxAttempts
+func (p Policy) lockoutFor(failures int) time.Duration {
+	exponent := failures - p.MaxAttempts
+	if exponent < 0 {
+		return 0
+	}
+	if exponent > 30 {
+		return p.MaxLockout
+	}
+	d := p.BaseLockout * time.Duration(1<<exponent)
+	if d <= 0 || (p.MaxLockout > 0 && d > p.MaxLockout) {
+		return p.MaxLockout
+	}
+	return d
+}
+
+// LockedError is returned while a username or IP is locked out
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return errLocked.Error()
+}
+
+// Unwrap lets the API translate the error
+func (e *LockedError) Unwrap() error {
+	return errLocked
+}
+
+var errLocked = i18n.NewError("account_locked")
+
+// RetryAfterSeconds rounds RetryAfter up for the Retry-After header
+func (e *LockedError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// Guard tracks failed logins per username and per client IP
+type Guard struct {
+	store      Store
+	userPolicy Policy
+	ipPolicy   Policy
+	now        func() time.Time
+}
+
+// NewGuard creates a Guard using the given store and policies
+func NewGuard(store Store, userPolicy, ipPolicy Policy) *Guard {
+	return &Guard{
+		store:      store,
+		userPolicy: userPolicy,
+		ipPolicy:   ipPolicy,
+		now:        time.Now,
+	}
+}
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns a *LockedError if either the username or IP is currently locked
+func (g *Guard) Check(username, ip string) error {
+	now := g.now()
+	var retryAfter time.Duration
+
+	for _, key := range g.keys(username, ip) {
+		rec, err := g.store.Get(key)
+		if err != nil {
+			return fmt.Errorf("failed to read login attempts: %w", err)
+		}
+		if wait := rec.LockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against both the username and the IP
+func (g *Guard) RecordFailure(username, ip string) error {
+	now := g.now()
+
+	if g.userPolicy.MaxAttempts > 0 {
+		if err := g.recordFailure(userKey(username), g.userPolicy, now); err != nil {
+			return err
+		}
+	}
+	if g.ipPolicy.MaxAttempts > 0 && ip != "" {
+		if err := g.recordFailure(ipKey(ip), g.ipPolicy, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Guard) recordFailure(key string, policy Policy, now time.Time) error {
+	_, err := g.store.Update(key, func(rec *Record) {
+		if !rec.ExpiresAt.IsZero() && now.After(rec.ExpiresAt) {
+			*rec = Record{}
+		}
+
+		rec.Failures++
+		rec.LastFailure = now
+		rec.ExpiresAt = now.Add(policy.Window)
+
+		if rec.Failures >= policy.MaxAttempts {
+			rec.LockedUntil = now.Add(policy.lockoutFor(rec.Failures))
+			// Keep the count until a window after the lockout ends so the next failure escalates
+			rec.ExpiresAt = rec.LockedUntil.Add(policy.Window)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
+
+// RecordSuccess clears the username counter after a successful login
+// The IP counter is left alone so one valid account can't reset it for an attacker
+func (g *Guard) RecordSuccess(username string) error {
+	return g.store.Delete(userKey(username))
+}
+
+// Unlock clears any lockout for the given username
+func (g *Guard) Unlock(username string) error {
+	return g.store.Delete(userKey(username))
+}
+
+func (g *Guard) keys(username, ip string) []string {
+	var keys []string
+	if g.userPolicy.MaxAttempts > 0 {
+		keys = append(keys, userKey(username))
+	}
+	if g.ipPolicy.MaxAttempts > 0 && ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
@@ -0,0 +1,102 @@
+package lockout
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPolicyLockoutFor(t *testing.T) {
+	policy := Policy{MaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: 10 * time.Minute}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 4, want: 0},
+		{failures: 5, want: time.Minute},
+		{failures: 6, want: 2 * time.Minute},
+		{failures: 8, want: 8 * time.Minute},
+		{failures: 9, want: 10 * time.Minute},
+		{failures: 100, want: 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := policy.lockoutFor(tt.failures); got != tt.want {
+			t.Errorf("lockoutFor(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestGuard(t *testing.T) {
+	userPolicy := Policy{MaxAttempts: 3, Window: 15 * time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+	ipPolicy := Policy{MaxAttempts: 5, Window: 15 * time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+
+	tests := []struct {
+		name       string
+		failures   int           // failed logins for "alice" from 10.0.0.1
+		advance    time.Duration // time passing after the failures
+		success    bool          // a successful login after the failures
+		username   string        // checked username
+		ip         string        // checked IP
+		wantLocked bool
+	}{
+		{name: "below the limit", failures: 2, username: "alice", ip: "10.0.0.1"},
+		{name: "limit reached", failures: 3, username: "alice", ip: "10.0.0.1", wantLocked: true},
+		{name: "username is case-insensitive", failures: 3, username: " Alice ", ip: "10.0.0.2", wantLocked: true},
+		{name: "other user from another IP", failures: 3, username: "bob", ip: "10.0.0.2"},
+		{name: "IP limit locks other users", failures: 5, username: "bob", ip: "10.0.0.1", wantLocked: true},
+		{name: "lockout expires", failures: 3, advance: 2 * time.Minute, username: "alice", ip: "10.0.0.1"},
+		{name: "escalates", failures: 4, advance: time.Minute + time.Second, username: "alice", ip: "10.0.0.1", wantLocked: true},
+		{name: "success clears the username", failures: 3, success: true, username: "alice", ip: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			guard := NewGuard(NewMemoryStore(), userPolicy, ipPolicy)
+			guard.now = func() time.Time { return now }
+
+			for i := 0; i < tt.failures; i++ {
+				if err := guard.RecordFailure("alice", "10.0.0.1"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.success {
+				if err := guard.RecordSuccess("alice"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			now = now.Add(tt.advance)
+
+			err := guard.Check(tt.username, tt.ip)
+			var locked *LockedError
+			if gotLocked := errors.As(err, &locked); gotLocked != tt.wantLocked {
+				t.Fatalf("Check() = %v, want locked %v", err, tt.wantLocked)
+			}
+			if locked != nil && locked.RetryAfterSeconds() <= 0 {
+				t.Errorf("RetryAfterSeconds() = %d, want > 0", locked.RetryAfterSeconds())
+			}
+		})
+	}
+}
+
+func TestMemoryStoreUpdateIsAtomic(t *testing.T) {
+	store := NewMemoryStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Update("user:alice", func(rec *Record) {
+				rec.Failures++
+				rec.ExpiresAt = time.Now().Add(time.Hour)
+			})
+		}()
+	}
+	wg.Wait()
+
+	rec, _ := store.Get("user:alice")
+	if rec.Failures != 50 {
+		t.Errorf("Failures = %d, want 50", rec.Failures)
+	}
+}
@@ -0,0 +1,145 @@
+package lockout
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"tiger-fasttrack-card/internal/database"
+	"tiger-fasttrack-card/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Record is the failed-attempt state kept for a single key
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// Store persists failed-attempt records
+// Update must apply fn atomically so concurrent failures are not lost
+type Store interface {
+	Get(key string) (Record, error)
+	Update(key string, fn func(*Record)) (Record, error)
+	Delete(key string) error
+}
+
+// MemoryStore keeps records in process memory
+// Suitable for a single instance; records are lost on restart
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastPrune time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]Record),
+	}
+}
+
+func (s *MemoryStore) Get(key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *MemoryStore) Update(key string, fn func(*Record)) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+
+	rec := s.records[key]
+	fn(&rec)
+	s.records[key] = rec
+	return rec, nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// pruneLocked drops expired records at most once a minute
+func (s *MemoryStore) pruneLocked(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	for key, rec := range s.records {
+		if now.After(rec.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+	s.lastPrune = now
+}
+
+// PostgresStore keeps records in the login_attempts table
+// so lockouts are shared between instances and survive restarts
+type PostgresStore struct {
+	db *database.Database
+}
+
+// NewPostgresStore creates a store backed by the application database
+func NewPostgresStore(db *database.Database) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Get(key string) (Record, error) {
+	var attempt models.LoginAttempt
+	err := s.db.GetDB().Where("attempt_key = ?", key).First(&attempt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Record{}, nil
+		}
+		return Record{}, err
+	}
+	return toRecord(attempt), nil
+}
+
+func (s *PostgresStore) Update(key string, fn func(*Record)) (Record, error) {
+	var rec Record
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Create the row first so the lock below always has a row to hold; otherwise two
+		// first failures for the same key would both start from zero and one would be lost
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+
+		var attempt models.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("attempt_key = ?", key).First(&attempt).Error; err != nil {
+			return err
+		}
+
+		rec = toRecord(attempt)
+		fn(&rec)
+
+		return tx.Model(&attempt).Updates(map[string]any{
+			"failures":     rec.Failures,
+			"last_failure": rec.LastFailure,
+			"locked_until": rec.LockedUntil,
+			"expires_at":   rec.ExpiresAt,
+		}).Error
+	})
+	return rec, err
+}
+
+func (s *PostgresStore) Delete(key string) error {
+	return s.db.GetDB().Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func toRecord(attempt models.LoginAttempt) Record {
+	return Record{
+		Failures:    attempt.Failures,
+		LastFailure: attempt.LastFailure,
+		LockedUntil: attempt.LockedUntil,
+		ExpiresAt:   attempt.ExpiresAt,
+	}
+}
@@ -0,0 +1,93 @@
+// Package metrics exposes Prometheus metrics: HTTP traffic, the database connection
+// pool, Go runtime statistics and business counters incremented by the services.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tiger_card"
+
+// Registry holds every metric this service exports; the default Prometheus registry
+// isn't used so libraries can't add metrics behind our back
+var Registry = prometheus.NewRegistry()
+
+// HTTP traffic, labelled by route template (e.g. /api/v1/cards/:id) rather than the raw
+// path so IDs don't create a series each
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+)
+
+// Business counters
+var (
+	RegistrationsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "card_registrations_created_total",
+		Help:      "Card registrations created, by the role of the user or API client registering them.",
+	}, []string{"role"})
+
+	LoginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Failed logins by reason: password, two_factor, client_secret or locked_out.",
+	}, []string{"reason"})
+
+	DuplicateRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "duplicate_registrations_rejected_total",
+		Help:      "Card numbers rejected because they are already registered for the card.",
+	})
+)
+
+// Login failure reasons
+const (
+	LoginFailurePassword     = "password"
+	LoginFailureTwoFactor    = "two_factor"
+	LoginFailureClientSecret = "client_secret"
+	LoginFailureLockedOut    = "locked_out"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		RegistrationsCreated,
+		LoginFailures,
+		DuplicateRejections,
+	)
+}
+
+// RegisterDB exports the connection pool statistics of db (sql.DB.Stats)
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSOptions is the cross-origin policy for browser clients
+type CORSOptions struct {
+	// AllowedOrigins are exact origins ("https://app.example.com"), patterns where * stands
+	// for subdomains or a port ("https://*.example.com", "http://localhost:*"), or "*" for
+	// any origin; empty allows none
+	AllowedOrigins   []string
+	AllowCredentials bool
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string      // response headers scripts may read
+	MaxAge           time.Duration // how long browsers may cache a preflight response
+}
+
+// CORS answers preflight requests and adds CORS headers for allowed origins. The allowed
+// origin is echoed rather than sent as *, so responses carry Vary: Origin for caches.
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	anyOrigin := false
+	var patterns []*regexp.Regexp
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+			continue
+		}
+		patterns = append(patterns, originPattern(origin))
+	}
+
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge / time.Second))
+
+	allowed := func(origin string) bool {
+		origin = strings.ToLower(origin)
+		for _, pattern := range patterns {
+			if pattern.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && origin != "" &&
+			c.GetHeader("Access-Control-Request-Method") != ""
+
+		header := c.Writer.Header()
+		if !anyOrigin {
+			// Whether CORS headers are sent depends on the origin, even when it's refused
+			header.Add("Vary", "Origin")
+		}
+
+		switch {
+		case origin == "":
+			c.Next()
+			return
+		case anyOrigin:
+			header.Set("Access-Control-Allow-Origin", "*")
+		case allowed(origin):
+			header.Set("Access-Control-Allow-Origin", origin)
+			if opts.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+		default:
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// Browsers block the response; other clients aren't affected by CORS
+			c.Next()
+			return
+		}
+
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", methods)
+			header.Set("Access-Control-Allow-Headers", headers)
+			if opts.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposed != "" {
+			header.Set("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	})
+}
+
+// originPattern matches an origin case-insensitively; * matches a port number after a
+// colon and one or more host labels elsewhere, never a scheme or path
+func originPattern(origin string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(strings.ToLower(origin))
+	quoted = strings.ReplaceAll(quoted, `:\*`, `:[0-9]+`)
+	quoted = strings.ReplaceAll(quoted, `\*`, `[a-z0-9-]+(?:\.[a-z0-9-]+)*`)
+	return regexp.MustCompile("^" + quoted + "$")
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// languageKey is the context key holding the negotiated response language
+const languageKey = "lang"
+
+// Language picks the response language from the Accept-Language header
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(languageKey, lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// Lang returns the language negotiated for the request
+func Lang(c *gin.Context) string {
+	if lang := c.GetString(languageKey); lang != "" {
+		return lang
+	}
+	return i18n.Default
+}
+
+// ErrorBody is the JSON body for an error response: the message translated into the
+// request's language and its code
+func ErrorBody(c *gin.Context, code string, args ...any) gin.H {
+	return WithTraceID(c, gin.H{"error": i18n.T(Lang(c), code, args...), "code": code})
+}
+
+// WithTraceID adds the request's trace ID to an error body so a report can be matched
+// to its trace
+func WithTraceID(c *gin.Context, body gin.H) gin.H {
+	if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+		body["trace_id"] = traceID
+	}
+	return body
+}
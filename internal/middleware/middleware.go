@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 	"tiger-fasttrack-card/internal/utils"
@@ -9,29 +8,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// CORS middleware to handle Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	})
-}
-
 // AuthMiddleware for JWT authentication
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// API client tokens are rejected unless clientScopes are given, in which case the
+// client must have been granted all of them
+func AuthMiddleware(jwtManager *utils.JWTManager, clientScopes ...string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, "authorization_required"))
 			c.Abort()
 			return
 		}
@@ -39,18 +23,42 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		// Extract token from "Bearer <token>"
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, "invalid_authorization_header"))
 			c.Abort()
 			return
 		}
 
 		tokenString := tokenParts[1]
-		
-		jwtManager := utils.NewJWTManager(jwtSecret)
-		
+
 		claims, err := jwtManager.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, "invalid_token"))
+			c.Abort()
+			return
+		}
+
+		switch claims.TokenType {
+		case "", utils.TokenTypeAccess:
+			// User access token
+		case utils.TokenTypeClient:
+			if len(clientScopes) == 0 {
+				c.JSON(http.StatusForbidden, ErrorBody(c, "api_client_not_allowed"))
+				c.Abort()
+				return
+			}
+			for _, scope := range clientScopes {
+				if !claims.HasScope(scope) {
+					body := ErrorBody(c, "insufficient_scope", scope)
+					body["required_scope"] = scope
+					c.JSON(http.StatusForbidden, body)
+					c.Abort()
+					return
+				}
+			}
+			c.Set("client_id", claims.ClientID)
+		default:
+			// Refresh and two-factor challenge tokens can't be used as access tokens
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, "invalid_token"))
 			c.Abort()
 			return
 		}
@@ -63,20 +71,3 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Next()
 	})
 }
-
-// LoggerMiddleware for custom logging
-func LoggerMiddleware() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format("02/Jan/2006:15:04:05 -0700"),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	})
-}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"tiger-fasttrack-card/internal/ratelimit"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit throttles requests with a token bucket per caller
+// Callers are identified by user ID when AuthMiddleware ran first, otherwise by client IP.
+// A nil store disables throttling.
+func RateLimit(store ratelimit.Store, name string, policy ratelimit.Policy) gin.HandlerFunc {
+	return RateLimitReadWrite(store, name, policy, policy)
+}
+
+// RateLimitReadWrite applies the read policy to GET/HEAD/OPTIONS and the write policy to everything else
+func RateLimitReadWrite(store ratelimit.Store, name string, read, write ratelimit.Policy) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if store == nil {
+			c.Next()
+			return
+		}
+
+		policy, bucket := write, name+":write"
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			policy, bucket = read, name+":read"
+		}
+		if !policy.Enabled() {
+			c.Next()
+			return
+		}
+
+		result, err := store.Take(c.Request.Context(), bucket+":"+rateLimitKey(c), policy)
+		if err != nil {
+			// Fail open: an unavailable limiter backend shouldn't take the API down with it
+			slog.WarnContext(c.Request.Context(), "rate limiter unavailable", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Burst, ceilSeconds(policy.Window())))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			body := ErrorBody(c, "too_many_requests")
+			body["retry_after"] = retryAfter
+			c.JSON(http.StatusTooManyRequests, body)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// rateLimitKey identifies the caller for bucketing
+func rateLimitKey(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
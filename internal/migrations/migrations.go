@@ -1,18 +1,256 @@
 package migrations
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
 	"tiger-fasttrack-card/internal/database"
 	"tiger-fasttrack-card/internal/models"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// migration is one step of RunMigrations. Every step runs on each migration and must be
+// safe to repeat; a step's data changes check whether they're still needed.
+type migration struct {
+	name string
+	run  func(db *gorm.DB) error
+}
+
+// registered lists the steps in the order they run. Append a step for every change to the
+// models or data, even one that only migrates the changed models, so SchemaVersion moves
+// and readiness fails until the database has been migrated.
+var registered = []migration{
+	// Data migrations that reshape a table run before its model is migrated
+	{name: "people identity documents", run: migratePeopleToDocuments},
+	{name: "card prices in minor units", run: migrateCardPricesToMinorUnits},
+	{name: "models", run: migrateModels},
+	{name: "card owners to people", run: migrateCardOwnersToPeople},
+	{name: "card owners", run: func(db *gorm.DB) error { return db.AutoMigrate(&models.CardOwner{}) }},
+	{name: "tenants", run: migrateToTenants},
+	{name: "card quantities from the stock ledger", run: reconcileCardQuantities},
+}
+
+// SchemaVersion identifies the schema RunMigrations produces: the number of registered steps
+var SchemaVersion = len(registered)
+
+// schemaMigration records the highest schema version migrated to
+type schemaMigration struct {
+	ID        uint `gorm:"primaryKey"`
+	Version   int  `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
 // RunMigrations executes all database migrations
 func RunMigrations(db *database.Database) error {
+	for _, step := range registered {
+		if err := step.run(db.GetDB()); err != nil {
+			return fmt.Errorf("migration %q: %w", step.name, err)
+		}
+	}
+	return recordSchemaVersion(db.GetDB())
+}
+
+// migrateModels creates and updates the tables of every model but CardOwner, whose
+// migration depends on people being filled in first
+func migrateModels(db *gorm.DB) error {
 	// Add your models here when you create them
-	return db.GetDB().AutoMigrate(
+	return db.AutoMigrate(
+		&models.Organization{},
 		&models.User{},
-		&models.CardOwner{},
+		&models.Person{},
 		&models.Card{},
+		&models.LoginAttempt{},
+		&models.PasswordHistory{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
+		&models.SigningKey{},
+		&models.APIClient{},
+		&models.Invitation{},
+		&models.VerificationCode{},
+		&models.CardNumberRange{},
+		&models.Branch{},
+		&models.CardBatch{},
+		&models.CardSerial{},
+		&models.StockMovement{},
 		// Add other models here as you create them
 		// &models.Transaction{},
 	)
 }
+
+// recordSchemaVersion stores SchemaVersion unless a newer build has already migrated further
+func recordSchemaVersion(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	var applied schemaMigration
+	if err := db.Limit(1).Find(&applied, 1).Error; err != nil {
+		return err
+	}
+	if applied.Version >= SchemaVersion {
+		return nil
+	}
+	return db.Save(&schemaMigration{ID: 1, Version: SchemaVersion, AppliedAt: time.Now()}).Error
+}
+
+// CheckPending returns an error when the database hasn't been migrated to SchemaVersion
+func CheckPending(ctx context.Context, db *database.Database) error {
+	var applied schemaMigration
+	if err := db.GetDB().WithContext(ctx).Limit(1).Find(&applied, 1).Error; err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if applied.Version < SchemaVersion {
+		return fmt.Errorf("database schema is at version %d, expected %d", applied.Version, SchemaVersion)
+	}
+	return nil
+}
+
+// migratePeopleToDocuments renames people.national_id to document_number; AutoMigrate
+// then adds the document type and issuing country, defaulting existing rows to Thai
+// national IDs, and the composite unique index.
+func migratePeopleToDocuments(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("people") || !migrator.HasColumn("people", "national_id") {
+		return nil
+	}
+
+	if migrator.HasIndex("people", "idx_people_national_id") {
+		if err := migrator.DropIndex("people", "idx_people_national_id"); err != nil {
+			return err
+		}
+	}
+	return migrator.RenameColumn("people", "national_id", "document_number")
+}
+
+// migrateCardPricesToMinorUnits converts cards.price from numeric baht to whole satang (minor
+// units), so prices no longer pass through floating point
+func migrateCardPricesToMinorUnits(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("cards") || !migrator.HasColumn("cards", "price") {
+		return nil
+	}
+
+	columns, err := migrator.ColumnTypes("cards")
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() == "price" && strings.EqualFold(column.DatabaseTypeName(), "numeric") {
+			return db.Exec(`ALTER TABLE cards ALTER COLUMN price TYPE bigint USING ROUND(price * 100)`).Error
+		}
+	}
+	return nil
+}
+
+// reconcileCardQuantities resets cards.card_quantity to the number of in-stock serials in
+// the stock ledger. Quantities entered by hand before the ledger existed are logged before
+// they're replaced, so the missing serials can be received as batches.
+func reconcileCardQuantities(db *gorm.DB) error {
+	var stale []struct {
+		ID           uint
+		Name         string
+		CardQuantity int
+		InStock      int
+	}
+	err := db.Raw(`SELECT cards.id, cards.card_name AS name, cards.card_quantity, COUNT(card_serials.id) AS in_stock
+		FROM cards LEFT JOIN card_serials ON card_serials.card_id = cards.id AND card_serials.state = ?
+		GROUP BY cards.id HAVING cards.card_quantity <> COUNT(card_serials.id)`, models.SerialInStock).
+		Scan(&stale).Error
+	if err != nil {
+		return err
+	}
+
+	for _, card := range stale {
+		slog.Warn("card quantity does not match the stock ledger; using the ledger",
+			"card_id", card.ID, "card", card.Name, "card_quantity", card.CardQuantity, "in_stock", card.InStock)
+		err := db.Exec(`UPDATE cards SET card_quantity = (SELECT COUNT(*) FROM card_serials WHERE card_id = ? AND state = ?) WHERE id = ?`,
+			card.ID, models.SerialInStock, card.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCardOwnersToPeople moves the ID card and phone number duplicated on every
+// card_owners row into one people row per normalized document number. Existing
+// registrations predate other document types, so all of them are Thai national IDs.
+// When a person's rows disagree on the phone number, the most recently updated row wins.
+func migrateCardOwnersToPeople(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("card_owners") || !migrator.HasColumn("card_owners", "id_card") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Same normalization as models.NormalizeDocumentNumber
+		const normalized = `UPPER(REGEXP_REPLACE(id_card, '[[:space:]-]', '', 'g'))`
+
+		statements := []string{
+			`ALTER TABLE card_owners ADD COLUMN IF NOT EXISTS person_id bigint`,
+			`INSERT INTO people (document_type, issuing_country, document_number, phone_number, created_at, updated_at)
+			 SELECT DISTINCT ON (document_number) 'national_id', 'TH', document_number, phone_number, created_at, NOW()
+			 FROM (SELECT ` + normalized + ` AS document_number, phone_number, created_at, updated_at FROM card_owners) owners
+			 ORDER BY document_number, updated_at DESC
+			 ON CONFLICT (document_type, issuing_country, document_number) DO NOTHING`,
+			`UPDATE card_owners SET person_id = people.id
+			 FROM people WHERE people.document_type = 'national_id' AND people.issuing_country = 'TH'
+			 AND people.document_number = ` + normalized,
+			`ALTER TABLE card_owners DROP COLUMN id_card, DROP COLUMN phone_number`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		var people int64
+		tx.Model(&models.Person{}).Count(&people)
+		slog.Info("Migrated card owners to people", "people", people)
+		return nil
+	})
+}
+
+// migrateToTenants runs once, when the organizations table is still empty: it creates the
+// default organization and moves all existing users and data into it. Existing admins stay
+// admins of that organization; super admins are created with `user create --role super_admin`.
+func migrateToTenants(db *gorm.DB) error {
+	var organizations int64
+	if err := db.Model(&models.Organization{}).Unscoped().Count(&organizations).Error; err != nil {
+		return err
+	}
+	if organizations > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		org := &models.Organization{
+			Code:     models.DefaultOrganizationCode,
+			Name:     "Head Office",
+			Type:     models.OrganizationCompany,
+			IsActive: true,
+		}
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`UPDATE users SET organization_id = ? WHERE organization_id IS NULL AND role <> ?`, org.ID, models.RoleSuperAdmin).Error; err != nil {
+			return err
+		}
+		for _, table := range []string{"card_owners", "branches", "card_batches", "card_serials", "invitations"} {
+			if err := tx.Exec(`UPDATE `+table+` SET organization_id = ? WHERE organization_id IS NULL OR organization_id = 0`, org.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		slog.Info("Created organization and moved existing data into it", "organization", org.Code)
+		return nil
+	})
+}
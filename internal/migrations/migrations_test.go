@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"tiger-fasttrack-card/internal/config"
+	"tiger-fasttrack-card/internal/database"
+	"tiger-fasttrack-card/internal/models"
+)
+
+// testDatabase connects to the database named by TEST_DATABASE_URL and migrates it; tests
+// using it are skipped without a database
+func testDatabase(t *testing.T) *database.Database {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	cfg := config.Default("test")
+	cfg.DatabaseURL = url
+	db, err := database.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() = %v", err)
+	}
+	return db
+}
+
+func TestRunMigrationsTwice(t *testing.T) {
+	db := testDatabase(t)
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("second RunMigrations() = %v", err)
+	}
+	if err := CheckPending(context.Background(), db); err != nil {
+		t.Errorf("CheckPending() = %v", err)
+	}
+}
+
+func TestReconcileCardQuantities(t *testing.T) {
+	db := testDatabase(t).GetDB()
+
+	card := &models.Card{
+		CardName:     fmt.Sprintf("Reconcile %d", time.Now().UnixNano()),
+		CardImage:    "data:image/png;base64,",
+		CardQuantity: 7, // Entered by hand before the ledger
+	}
+	if err := db.Create(card).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Where("card_id = ?", card.ID).Delete(&models.CardSerial{})
+		db.Unscoped().Delete(card)
+	})
+	serials := []models.CardSerial{
+		{CardID: card.ID, Serial: "A1", State: models.SerialInStock},
+		{CardID: card.ID, Serial: "A2", State: models.SerialInStock},
+		{CardID: card.ID, Serial: "A3", State: models.SerialIssued},
+	}
+	if err := db.Create(&serials).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reconcileCardQuantities(db); err != nil {
+		t.Fatalf("reconcileCardQuantities() = %v", err)
+	}
+	var reconciled models.Card
+	if err := db.First(&reconciled, card.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reconciled.CardQuantity != 2 {
+		t.Errorf("card_quantity = %d, want the 2 in-stock serials", reconciled.CardQuantity)
+	}
+}
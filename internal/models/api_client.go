@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleAPIClient is the role of the service user behind each API client
+const RoleAPIClient = "api_client"
+
+// Scopes that can be granted to API clients
+const (
+	ScopeCardOwnersRead     = "card_owners:read"
+	ScopeCardOwnersRegister = "card_owners:register"
+)
+
+// AllowedScopes lists every scope an API client may be granted
+var AllowedScopes = []string{ScopeCardOwnersRead, ScopeCardOwnersRegister}
+
+// APIClient is a partner system using the OAuth2 client-credentials grant
+// Registrations made by a client are owned by its service user (UserID)
+type APIClient struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	ClientID    string         `json:"client_id" gorm:"not null;uniqueIndex"`
+	Name        string         `json:"name" gorm:"not null"`
+	SecretHash  string         `json:"-" gorm:"not null"`
+	Scopes      string         `json:"scopes" gorm:"not null"` // space-separated, as in OAuth2
+	UserID      uint           `json:"user_id" gorm:"not null"`
+	User        User           `json:"-" gorm:"foreignKey:UserID"`
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	CreatedByID uint           `json:"created_by_id"`
+	LastUsedAt  *time.Time     `json:"last_used_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreateAPIClientRequest represents the request body for registering an API client
+type CreateAPIClientRequest struct {
+	Name           string   `json:"name" binding:"required"`
+	Scopes         []string `json:"scopes" binding:"required,min=1"`
+	OrganizationID *uint    `json:"organization_id,omitempty"` // Super admins only; defaults to the caller's organization
+	BranchID       *uint    `json:"branch_id,omitempty"`       // Defaults to the caller's branch
+}
+
+// APIClientCredentials is returned when a client is created or its secret rotated
+// The secret is only ever shown here
+type APIClientCredentials struct {
+	Client       APIClient `json:"client"`
+	ClientSecret string    `json:"client_secret"`
+}
+
+// ClientTokenRequest is an OAuth2 token request (RFC 6749 section 4.4)
+// Credentials may also be sent with HTTP Basic authentication
+type ClientTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" binding:"required"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+	Scope        string `form:"scope" json:"scope"`
+}
+
+// ClientTokenResponse is an OAuth2 access token response
+type ClientTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"tiger-fasttrack-card/internal/cardnumber"
+	"tiger-fasttrack-card/internal/i18n"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,25 +10,100 @@ import (
 
 // Card represents a card in the system (Master Data)
 type Card struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	CardName     string         `json:"card_name" gorm:"not null;uniqueIndex"` // Master data - unique card names
-	CardImage    string         `json:"card_image" gorm:"not null"`
-	CardQuantity int            `json:"card_quantity" gorm:"not null;default:0"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CardName      string         `json:"card_name" gorm:"not null;uniqueIndex"` // Master data - unique card names, in English
+	CardNameTH    string         `json:"card_name_th"`
+	CardImage     string         `json:"card_image" gorm:"not null"`
+	CardQuantity  int            `json:"card_quantity" gorm:"not null;default:0"` // In-stock serials, derived from the stock ledger
+	Category      string         `json:"category" gorm:"index"`                   // e.g. airport, lifestyle
+	Tier          string         `json:"tier" gorm:"index"`                       // e.g. Gold, Platinum
+	Price         int64          `json:"price" gorm:"not null;default:0"`         // In minor units of Currency, e.g. satang for THB
+	Currency      string         `json:"currency" gorm:"size:3;not null;default:'THB'"`
+	Benefits      []string       `json:"benefits" gorm:"type:jsonb;serializer:json;not null;default:'[]'"`
+	DescriptionTH string         `json:"description_th"`
+	DescriptionEN string         `json:"description_en"`
+	IsActive      bool           `json:"is_active" gorm:"not null;default:true;index"`
+	DisplayOrder  int            `json:"display_order" gorm:"not null;default:0;index"` // Lower values are listed first
+	NumberPrefix  string         `json:"number_prefix"`                                 // Card number scheme, see NumberScheme
+	NumberLength  int            `json:"number_length" gorm:"not null;default:0"`       // 0 allows free-form card numbers
+	CheckDigit    string         `json:"check_digit" gorm:"not null;default:'none'"`
+	NextSequence  int64          `json:"next_sequence" gorm:"not null;default:1"` // Next sequence the allocator issues
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Name and description in the request's language, see Localize
+	Name        string `json:"name,omitempty" gorm:"-"`
+	Description string `json:"description,omitempty" gorm:"-"`
+}
+
+// NumberScheme returns the card's numbering scheme
+func (c *Card) NumberScheme() cardnumber.Scheme {
+	return cardnumber.Scheme{Prefix: c.NumberPrefix, Length: c.NumberLength, CheckDigit: c.CheckDigit}
+}
+
+// Localize sets Name and Description for lang, falling back to the other language
+// when the card has no text in lang
+func (c *Card) Localize(lang string) {
+	c.Name = i18n.Localize(lang, map[string]string{i18n.EN: c.CardName, i18n.TH: c.CardNameTH})
+	c.Description = i18n.Localize(lang, map[string]string{i18n.EN: c.DescriptionEN, i18n.TH: c.DescriptionTH})
 }
 
 // CreateCardRequest represents the request body for creating a card
 type CreateCardRequest struct {
-	CardName     string `json:"card_name" binding:"required"`
-	CardImage    string `json:"card_image" binding:"required"`
-	CardQuantity int    `json:"card_quantity"`
+	CardName      string   `json:"card_name" binding:"required"` // English name
+	CardNameTH    string   `json:"card_name_th"`
+	CardImage     string   `json:"card_image" binding:"required"`
+	Category      string   `json:"category"`
+	Tier          string   `json:"tier"`
+	Price         int64    `json:"price" binding:"min=0"`              // Minor units, e.g. 250000 for 2,500.00 THB
+	Currency      string   `json:"currency" binding:"omitempty,len=3"` // Defaults to THB
+	Benefits      []string `json:"benefits"`
+	DescriptionTH string   `json:"description_th"`
+	DescriptionEN string   `json:"description_en"`
+	IsActive      *bool    `json:"is_active,omitempty"` // Defaults to true
+	DisplayOrder  int      `json:"display_order"`
+	NumberPrefix  string   `json:"number_prefix"`
+	NumberLength  int      `json:"number_length" binding:"min=0"`
+	CheckDigit    string   `json:"check_digit"` // none (default) or luhn
 }
 
 // UpdateCardRequest represents the request body for updating a card
+// Omitted fields are left unchanged; an empty benefits list clears the benefits
 type UpdateCardRequest struct {
-	CardName     string `json:"card_name"`
-	CardImage    string `json:"card_image"`
-	CardQuantity *int   `json:"card_quantity,omitempty" binding:"omitempty,min=0"`
+	CardName      string   `json:"card_name"`
+	CardNameTH    *string  `json:"card_name_th,omitempty"`
+	CardImage     string   `json:"card_image"`
+	Category      *string  `json:"category,omitempty"`
+	Tier          *string  `json:"tier,omitempty"`
+	Price         *int64   `json:"price,omitempty" binding:"omitempty,min=0"` // Minor units
+	Currency      string   `json:"currency" binding:"omitempty,len=3"`
+	Benefits      []string `json:"benefits"`
+	DescriptionTH *string  `json:"description_th,omitempty"`
+	DescriptionEN *string  `json:"description_en,omitempty"`
+	IsActive      *bool    `json:"is_active,omitempty"`
+	DisplayOrder  *int     `json:"display_order,omitempty"`
+	NumberPrefix  *string  `json:"number_prefix,omitempty"`
+	NumberLength  *int     `json:"number_length,omitempty" binding:"omitempty,min=0"`
+	CheckDigit    string   `json:"check_digit"`
+}
+
+// CardImport is one card of an import; an existing card's is_active only changes when the
+// imported file sets it
+type CardImport struct {
+	Card       *Card
+	SetsActive bool
+}
+
+// CardImportResult counts the cards an import added and the existing ones it updated
+type CardImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// CardFilter narrows the card list; empty fields match every card
+type CardFilter struct {
+	Category string
+	Tier     string
+	IsActive *bool
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"tiger-fasttrack-card/internal/cardnumber"
+	"time"
+)
+
+// MaxCardNumberRange is the most numbers one pre-generated range can hold
+const MaxCardNumberRange = 10000
+
+// CardNumberRange is a block of card numbers reserved for printed stock; the numbers
+// are the card's scheme at the time of generation applied to sequences FirstSequence
+// through LastSequence
+type CardNumberRange struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CardID        uint      `json:"card_id" gorm:"not null;index"`
+	FirstSequence int64     `json:"first_sequence" gorm:"not null"`
+	LastSequence  int64     `json:"last_sequence" gorm:"not null"`
+	FirstNumber   string    `json:"first_number" gorm:"not null"`
+	LastNumber    string    `json:"last_number" gorm:"not null"`
+	Count         int       `json:"count" gorm:"not null"`
+	NumberPrefix  string    `json:"number_prefix"`
+	NumberLength  int       `json:"number_length" gorm:"not null"`
+	CheckDigit    string    `json:"check_digit" gorm:"not null"`
+	Note          string    `json:"note"`
+	CreatedByID   uint      `json:"created_by_id" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	Numbers       []string  `json:"numbers,omitempty" gorm:"-"` // Filled when a single range is requested
+}
+
+// NumberScheme returns the scheme the range's numbers were generated with
+func (r *CardNumberRange) NumberScheme() cardnumber.Scheme {
+	return cardnumber.Scheme{Prefix: r.NumberPrefix, Length: r.NumberLength, CheckDigit: r.CheckDigit}
+}
+
+// CreateCardNumberRangeRequest represents the request body for pre-generating card numbers
+type CreateCardNumberRangeRequest struct {
+	Count int    `json:"count" binding:"required,min=1"`
+	Note  string `json:"note"`
+}
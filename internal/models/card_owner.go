@@ -8,46 +8,86 @@ import (
 
 // CardOwner represents a card owner in the system
 type CardOwner struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	CardID      uint           `json:"card_id" gorm:"not null;index"` // References Card master data by ID
-	Card        Card           `json:"card" gorm:"foreignKey:CardID"`
-	CardNumber  string         `json:"card_number" gorm:"not null;uniqueIndex:idx_card_number_card_id"`
-	IDCard      string         `json:"id_card" gorm:"not null;index"` // Removed uniqueIndex to allow same person to have multiple cards
-	PhoneNumber string         `json:"phone_number" gorm:"not null"`
-	UserID      uint           `json:"user_id" gorm:"not null"`
-	User        User           `json:"user" gorm:"foreignKey:UserID"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	CardID         uint           `json:"card_id" gorm:"not null;index"` // References Card master data by ID
+	Card           Card           `json:"card" gorm:"foreignKey:CardID"`
+	CardNumber     string         `json:"card_number" gorm:"not null;uniqueIndex:idx_card_number_card_id"`
+	PersonID       uint           `json:"person_id" gorm:"not null;index"` // Card holder; same person can have multiple cards
+	Person         *Person        `json:"person,omitempty" gorm:"foreignKey:PersonID"`
+	IDCard         string         `json:"id_card" gorm:"-"`         // Person's document number, kept for API compatibility
+	PhoneNumber    string         `json:"phone_number" gorm:"-"`    // From Person, kept for API compatibility
+	DocumentType   string         `json:"document_type" gorm:"-"`   // From Person
+	IssuingCountry string         `json:"issuing_country" gorm:"-"` // From Person
+	UserID         uint           `json:"user_id" gorm:"not null"`
+	User           User           `json:"user" gorm:"foreignKey:UserID"`
+	OrganizationID uint           `json:"organization_id" gorm:"index"` // Tenant the card was registered in
+	BranchID       *uint          `json:"branch_id,omitempty" gorm:"index"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// AfterFind fills the compatibility fields from the preloaded Person
+func (o *CardOwner) AfterFind(tx *gorm.DB) error {
+	if o.Person != nil {
+		o.SetHolder(o.Person)
+	}
+	return nil
+}
+
+// SetHolder copies the person's document and phone number into the compatibility fields
+func (o *CardOwner) SetHolder(person *Person) {
+	o.IDCard = person.DocumentNumber
+	o.DocumentType = person.DocumentType
+	o.IssuingCountry = person.IssuingCountry
+	o.PhoneNumber = person.PhoneNumber
 }
 
 // RegisterOwnerRequest represents the request body for registering a card owner
 type RegisterOwnerRequest struct {
-	CardID      uint   `json:"card_id" binding:"required"`
-	CardNumber  string `json:"card_number" binding:"required"`
-	IDCard      string `json:"id_card" binding:"required"`
-	PhoneNumber string `json:"phone_number" binding:"required"`
+	CardID         uint   `json:"card_id" binding:"required"`
+	CardNumber     string `json:"card_number"`                // Allocated when empty and the card has a numbering scheme
+	IDCard         string `json:"id_card" binding:"required"` // Document number
+	DocumentType   string `json:"document_type"`              // national_id (default), passport or other
+	IssuingCountry string `json:"issuing_country"`            // ISO 3166 alpha-2; defaults to TH for national IDs
+	PhoneNumber    string `json:"phone_number" binding:"required"`
+	BranchID       *uint  `json:"branch_id,omitempty"` // Defaults to the caller's branch
+}
+
+// Document returns the identity document given in the request
+func (r *RegisterOwnerRequest) Document() IdentityDocument {
+	return IdentityDocument{Type: r.DocumentType, Country: r.IssuingCountry, Number: r.IDCard}
 }
 
 // CardRegistration represents a single card registration item
 type CardRegistration struct {
 	CardID     uint   `json:"card_id" binding:"required"`
-	CardNumber string `json:"card_number" binding:"required"`
+	CardNumber string `json:"card_number"` // Allocated when empty and the card has a numbering scheme
 }
 
 // RegisterMultipleCardsRequest represents the request body for registering multiple cards
 type RegisterMultipleCardsRequest struct {
-	Cards       []CardRegistration `json:"cards" binding:"required,min=1"`
-	IDCard      string             `json:"id_card" binding:"required"`
-	PhoneNumber string             `json:"phone_number" binding:"required"`
+	Cards          []CardRegistration `json:"cards" binding:"required,min=1"`
+	IDCard         string             `json:"id_card" binding:"required"` // Document number
+	DocumentType   string             `json:"document_type"`
+	IssuingCountry string             `json:"issuing_country"`
+	PhoneNumber    string             `json:"phone_number" binding:"required"`
+	BranchID       *uint              `json:"branch_id,omitempty"` // Defaults to the caller's branch
+}
+
+// Document returns the identity document given in the request
+func (r *RegisterMultipleCardsRequest) Document() IdentityDocument {
+	return IdentityDocument{Type: r.DocumentType, Country: r.IssuingCountry, Number: r.IDCard}
 }
 
 // UpdateCardOwnerRequest represents the request body for updating a card owner
 type UpdateCardOwnerRequest struct {
-	CardID      uint   `json:"card_id"`
-	CardNumber  string `json:"card_number"`
-	IDCard      string `json:"id_card"`
-	PhoneNumber string `json:"phone_number"`
+	CardID         uint   `json:"card_id"`
+	CardNumber     string `json:"card_number"`
+	IDCard         string `json:"id_card"`         // Document number; moves the card to that person
+	DocumentType   string `json:"document_type"`   // Applies when id_card is given
+	IssuingCountry string `json:"issuing_country"` // Applies when id_card is given
+	PhoneNumber    string `json:"phone_number"`
 }
 
 // CardOwnerResponse represents the response for card owner operations
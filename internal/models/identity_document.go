@@ -0,0 +1,110 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"unicode"
+)
+
+// Identity document types for card holders
+const (
+	DocumentNationalID = "national_id"
+	DocumentPassport   = "passport"
+	DocumentOther      = "other"
+)
+
+// DefaultIssuingCountry is assumed for national IDs registered without a country
+const DefaultIssuingCountry = "TH"
+
+// IdentityDocument identifies a card holder; a person is unique per type, issuing
+// country and number
+type IdentityDocument struct {
+	Type    string `json:"document_type"`
+	Country string `json:"issuing_country"` // ISO 3166-1 alpha-2
+	Number  string `json:"document_number"`
+}
+
+// NormalizeDocumentNumber removes spaces and dashes and upper-cases the number so
+// "1-2345-67890-12-3" and "1234567890123" identify the same document
+func NormalizeDocumentNumber(number string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, number)
+}
+
+var (
+	countryCodePattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	passportNumberPattern = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+	nationalIDPattern     = regexp.MustCompile(`^[A-Z0-9]{5,20}$`)
+	otherDocumentPattern  = regexp.MustCompile(`^[A-Z0-9]{3,30}$`)
+)
+
+// Normalize applies defaults (national ID issued in Thailand), normalizes the number
+// and checks it against the rules for its type
+func (d IdentityDocument) Normalize() (IdentityDocument, error) {
+	doc := IdentityDocument{
+		Type:    strings.ToLower(strings.TrimSpace(d.Type)),
+		Country: strings.ToUpper(strings.TrimSpace(d.Country)),
+		Number:  NormalizeDocumentNumber(d.Number),
+	}
+	if doc.Type == "" {
+		doc.Type = DocumentNationalID
+	}
+	if doc.Country == "" && doc.Type == DocumentNationalID {
+		doc.Country = DefaultIssuingCountry
+	}
+
+	if doc.Number == "" {
+		return doc, i18n.NewError("document_number_required")
+	}
+	if doc.Country == "" {
+		return doc, i18n.NewError("issuing_country_required", doc.Type)
+	}
+	if !countryCodePattern.MatchString(doc.Country) {
+		return doc, i18n.NewError("invalid_issuing_country")
+	}
+
+	switch doc.Type {
+	case DocumentNationalID:
+		if doc.Country == "TH" {
+			if !validThaiNationalID(doc.Number) {
+				return doc, i18n.NewError("invalid_thai_national_id")
+			}
+		} else if !nationalIDPattern.MatchString(doc.Number) {
+			return doc, i18n.NewError("invalid_national_id")
+		}
+	case DocumentPassport:
+		if !passportNumberPattern.MatchString(doc.Number) {
+			return doc, i18n.NewError("invalid_passport_number")
+		}
+	case DocumentOther:
+		if !otherDocumentPattern.MatchString(doc.Number) {
+			return doc, i18n.NewError("invalid_document_number")
+		}
+	default:
+		return doc, i18n.NewError("invalid_document_type")
+	}
+
+	return doc, nil
+}
+
+// validThaiNationalID checks the 13-digit format and the mod-11 check digit
+func validThaiNationalID(id string) bool {
+	if len(id) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		if i < 12 {
+			sum += int(id[i]-'0') * (13 - i)
+		}
+	}
+	return int(id[12]-'0') == (11-sum%11)%10
+}
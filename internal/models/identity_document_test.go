@@ -0,0 +1,76 @@
+package models
+
+import "testing"
+
+func TestIdentityDocumentNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     IdentityDocument
+		want    IdentityDocument
+		wantErr bool
+	}{
+		{
+			name: "defaults to a Thai national ID",
+			doc:  IdentityDocument{Number: "1101700230708"},
+			want: IdentityDocument{Type: DocumentNationalID, Country: "TH", Number: "1101700230708"},
+		},
+		{
+			name: "dashes and spaces are removed",
+			doc:  IdentityDocument{Number: " 1-1017-00230-70-8 "},
+			want: IdentityDocument{Type: DocumentNationalID, Country: "TH", Number: "1101700230708"},
+		},
+		{name: "wrong Thai check digit", doc: IdentityDocument{Number: "1101700230709"}, wantErr: true},
+		{name: "short Thai national ID", doc: IdentityDocument{Number: "110170023070"}, wantErr: true},
+		{name: "letters in a Thai national ID", doc: IdentityDocument{Number: "11017002307A8"}, wantErr: true},
+		{
+			name: "foreign national ID",
+			doc:  IdentityDocument{Type: "National_ID", Country: "sg", Number: "s1234567d"},
+			want: IdentityDocument{Type: DocumentNationalID, Country: "SG", Number: "S1234567D"},
+		},
+		{
+			name: "passport",
+			doc:  IdentityDocument{Type: DocumentPassport, Country: "GB", Number: "123 456 789"},
+			want: IdentityDocument{Type: DocumentPassport, Country: "GB", Number: "123456789"},
+		},
+		{name: "passport needs a country", doc: IdentityDocument{Type: DocumentPassport, Number: "123456789"}, wantErr: true},
+		{name: "passport too long", doc: IdentityDocument{Type: DocumentPassport, Country: "GB", Number: "1234567890"}, wantErr: true},
+		{name: "country must be alpha-2", doc: IdentityDocument{Type: DocumentPassport, Country: "GBR", Number: "123456789"}, wantErr: true},
+		{
+			name: "other document",
+			doc:  IdentityDocument{Type: DocumentOther, Country: "TH", Number: "wp-001"},
+			want: IdentityDocument{Type: DocumentOther, Country: "TH", Number: "WP001"},
+		},
+		{name: "unknown type", doc: IdentityDocument{Type: "licence", Country: "TH", Number: "12345"}, wantErr: true},
+		{name: "missing number", doc: IdentityDocument{Number: " - "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.doc.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidThaiNationalID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "1101700230708", want: true},
+		{id: "3100501234563", want: true},
+		{id: "3100501234564"},
+		{id: "310050123456"},
+		{id: "31005012345630"},
+		{id: "310050123456x"},
+	}
+	for _, tt := range tests {
+		if got := validThaiNationalID(tt.id); got != tt.want {
+			t.Errorf("validThaiNationalID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// InvitableRoles lists the roles an invitation may grant
+var InvitableRoles = []string{"user", "admin"}
+
+// Invitation is an admin-issued, single-use registration code
+// Only the SHA-256 hash of the code is stored
+type Invitation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CodeHash    string     `json:"-" gorm:"not null;uniqueIndex"`
+	Role        string     `json:"role" gorm:"not null;default:'user'"`
+	Email       string     `json:"email"` // Optional; registration must use this address
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt      *time.Time `json:"used_at"`
+	UsedByID    *uint      `json:"used_by_id"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedByID uint       `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Tenant the new user joins
+	OrganizationID *uint `json:"organization_id,omitempty" gorm:"index"`
+	BranchID       *uint `json:"branch_id,omitempty"`
+}
+
+// CreateInvitationRequest represents the request body for issuing an invitation
+type CreateInvitationRequest struct {
+	Role           string `json:"role"` // Defaults to "user"
+	Email          string `json:"email" binding:"omitempty,email"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1"`
+	OrganizationID *uint  `json:"organization_id,omitempty"` // Super admins only; defaults to the caller's organization
+	BranchID       *uint  `json:"branch_id,omitempty"`       // Defaults to the caller's branch
+}
+
+// InvitationResponse carries the invitation code, which is only shown once
+type InvitationResponse struct {
+	Invitation Invitation `json:"invitation"`
+	Code       string     `json:"code"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt tracks failed logins for a username or client IP
+// Used by the Postgres-backed lockout store
+type LoginAttempt struct {
+	Key         string    `json:"key" gorm:"column:attempt_key;primaryKey;size:255"` // "user:<username>" or "ip:<address>"
+	Failures    int       `json:"failures" gorm:"not null;default:0"`
+	LastFailure time.Time `json:"last_failure"`
+	LockedUntil time.Time `json:"locked_until"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleSuperAdmin works across all organizations; admins only manage their own
+const RoleSuperAdmin = "super_admin"
+
+// Organization types
+const (
+	OrganizationCompany = "company" // Our own operation
+	OrganizationAgency  = "agency"  // Partner agency
+)
+
+// DefaultOrganizationCode is the organization existing data was migrated into; self-registered
+// and single sign-on users join it
+const DefaultOrganizationCode = "HQ"
+
+// Organization is a tenant: its branches, users, card registrations and stock are only
+// visible inside it
+type Organization struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Code      string         `json:"code" gorm:"not null;uniqueIndex"`
+	Name      string         `json:"name" gorm:"not null"`
+	Type      string         `json:"type" gorm:"not null;default:'company'"`
+	IsActive  bool           `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Tenant is the data a user works with: everything for super admins, otherwise one
+// organization or, for users assigned to a branch, one branch of it
+type Tenant struct {
+	All            bool
+	OrganizationID uint
+	BranchID       *uint
+}
+
+// Contains reports whether a row owned by the organization and branch is in the tenant
+func (t Tenant) Contains(organizationID uint, branchID *uint) bool {
+	if t.All {
+		return true
+	}
+	if organizationID != t.OrganizationID {
+		return false
+	}
+	return t.BranchID == nil || (branchID != nil && *branchID == *t.BranchID)
+}
+
+// CreateOrganizationRequest represents the request body for creating an organization
+type CreateOrganizationRequest struct {
+	Code string `json:"code" binding:"required"`
+	Name string `json:"name" binding:"required"`
+	Type string `json:"type"` // company (default) or agency
+}
+
+// UpdateOrganizationRequest represents the request body for updating an organization
+type UpdateOrganizationRequest struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	IsActive *bool  `json:"is_active,omitempty"`
+}
+
+// AssignTenantRequest moves a user to an organization and, optionally, one of its branches
+type AssignTenantRequest struct {
+	OrganizationID *uint `json:"organization_id,omitempty"` // Super admins only; defaults to the user's organization
+	BranchID       *uint `json:"branch_id"`                 // Null makes the user organization-wide
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordHistory stores previous password hashes so they can't be reused
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
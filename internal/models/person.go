@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Person is a card holder, identified by an identity document; contact details live
+// here once and are shared by all of the person's card registrations
+type Person struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	DocumentType   string         `json:"document_type" gorm:"not null;default:'national_id';uniqueIndex:idx_people_document"`
+	IssuingCountry string         `json:"issuing_country" gorm:"not null;default:'TH';uniqueIndex:idx_people_document"`
+	DocumentNumber string         `json:"document_number" gorm:"not null;uniqueIndex:idx_people_document"` // Stored normalized, see NormalizeDocumentNumber
+	PhoneNumber    string         `json:"phone_number" gorm:"not null"`
+	FirstName      string         `json:"first_name"`
+	LastName       string         `json:"last_name"`
+	Email          string         `json:"email"`
+	Cards          []CardOwner    `json:"cards,omitempty" gorm:"foreignKey:PersonID"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// UpdatePersonRequest represents the request body for updating a person's contact details
+type UpdatePersonRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email" binding:"omitempty,email"`
+}
+
+// Document returns the person's identity document
+func (p *Person) Document() IdentityDocument {
+	return IdentityDocument{Type: p.DocumentType, Country: p.IssuingCountry, Number: p.DocumentNumber}
+}
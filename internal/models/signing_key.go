@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a JWT signing key pair shared by all API instances
+// Keys are published (verifiable) as soon as they are created, used for signing
+// from ActivatesAt, and kept for verification until ExpiresAt
+type SigningKey struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	KID           string    `json:"kid" gorm:"column:kid;not null;uniqueIndex"`
+	Algorithm     string    `json:"algorithm" gorm:"not null"`
+	PrivateKeyPEM string    `json:"-" gorm:"type:text;not null"` // PKCS#8 PEM, encrypted with JWT_KEY_ENCRYPTION_KEY
+	ActivatesAt   time.Time `json:"activates_at" gorm:"not null"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Physical card serial states
+const (
+	SerialInStock  = "in_stock"
+	SerialIssued   = "issued"
+	SerialDamaged  = "damaged"
+	SerialReturned = "returned"
+)
+
+// SerialTransitions lists the states each serial state can move to
+var SerialTransitions = map[string][]string{
+	SerialInStock:  {SerialIssued, SerialDamaged},
+	SerialIssued:   {SerialReturned},
+	SerialReturned: {SerialInStock, SerialDamaged},
+	SerialDamaged:  {},
+}
+
+// MaxBatchSize is the most serials one batch or allocation can cover
+const MaxBatchSize = 10000
+
+// Branch is a location physical cards are allocated to
+type Branch struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	OrganizationID uint           `json:"organization_id" gorm:"index"`
+	Code           string         `json:"code" gorm:"not null;uniqueIndex"`
+	Name           string         `json:"name" gorm:"not null"`
+	IsActive       bool           `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CardBatch is a delivery of physical cards with consecutive serials
+type CardBatch struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	CardID         uint      `json:"card_id" gorm:"not null;index"`
+	NumberRangeID  *uint     `json:"number_range_id,omitempty"` // Printed from a pre-generated card number range
+	FirstSerial    string    `json:"first_serial" gorm:"not null"`
+	LastSerial     string    `json:"last_serial" gorm:"not null"`
+	Quantity       int       `json:"quantity" gorm:"not null"`
+	BranchID       *uint     `json:"branch_id,omitempty"` // Nil while held centrally
+	Supplier       string    `json:"supplier"`
+	Note           string    `json:"note"`
+	ReceivedByID   uint      `json:"received_by_id" gorm:"not null"`
+	ReceivedAt     time.Time `json:"received_at"`
+}
+
+// CardSerial is one physical card; its state and branch change only through stock movements
+type CardSerial struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	CardID         uint      `json:"card_id" gorm:"not null;uniqueIndex:idx_card_serial"`
+	Serial         string    `json:"serial" gorm:"not null;uniqueIndex:idx_card_serial"`
+	BatchID        uint      `json:"batch_id" gorm:"not null;index"`
+	State          string    `json:"state" gorm:"not null;index"`
+	BranchID       *uint     `json:"branch_id,omitempty" gorm:"index"`
+	CardOwnerID    *uint     `json:"card_owner_id,omitempty"` // Registration the card was last issued to
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Movements []StockMovement `json:"movements,omitempty" gorm:"-"` // Ledger history, oldest first
+}
+
+// NormalizeSerial trims and upper-cases a serial so lookups don't depend on how it was typed
+func NormalizeSerial(serial string) string {
+	return strings.ToUpper(strings.TrimSpace(serial))
+}
+
+// StockMovement is the ledger entry for a change to a serial
+type StockMovement struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CardID       uint      `json:"card_id" gorm:"not null;index"`
+	SerialID     uint      `json:"serial_id" gorm:"not null;index"`
+	Serial       string    `json:"serial" gorm:"not null"`
+	FromState    string    `json:"from_state"` // Empty when the serial was received
+	ToState      string    `json:"to_state" gorm:"not null"`
+	FromBranchID *uint     `json:"from_branch_id,omitempty"`
+	ToBranchID   *uint     `json:"to_branch_id,omitempty"`
+	CardOwnerID  *uint     `json:"card_owner_id,omitempty"`
+	Note         string    `json:"note"`
+	UserID       uint      `json:"user_id" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StockLevel counts the serials of a card in one state and branch
+type StockLevel struct {
+	CardID   uint   `json:"card_id"`
+	BranchID *uint  `json:"branch_id"`
+	State    string `json:"state"`
+	Count    int64  `json:"count"`
+}
+
+// StockFilter narrows serial and stock level queries; zero fields match everything
+type StockFilter struct {
+	CardID   uint
+	BranchID *uint
+	State    string
+}
+
+// CreateBranchRequest represents the request body for creating a branch
+type CreateBranchRequest struct {
+	Code           string `json:"code" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+	OrganizationID *uint  `json:"organization_id,omitempty"` // Super admins only; defaults to the caller's organization
+}
+
+// UpdateBranchRequest represents the request body for updating a branch
+type UpdateBranchRequest struct {
+	Name     string `json:"name"`
+	IsActive *bool  `json:"is_active,omitempty"`
+}
+
+// ReceiveBatchRequest represents a delivery of physical cards; give either a serial
+// range or a pre-generated card number range
+type ReceiveBatchRequest struct {
+	CardID         uint   `json:"card_id" binding:"required"`
+	FirstSerial    string `json:"first_serial"`
+	LastSerial     string `json:"last_serial"`
+	NumberRangeID  *uint  `json:"number_range_id,omitempty"`
+	BranchID       *uint  `json:"branch_id,omitempty"`       // Receive directly at a branch
+	OrganizationID *uint  `json:"organization_id,omitempty"` // Super admins only, when no branch is given
+	Supplier       string `json:"supplier"`
+	Note           string `json:"note"`
+}
+
+// AllocateStockRequest moves in-stock serials to a branch; give a serial range or a list
+type AllocateStockRequest struct {
+	CardID      uint     `json:"card_id" binding:"required"`
+	BranchID    uint     `json:"branch_id" binding:"required"`
+	FirstSerial string   `json:"first_serial"`
+	LastSerial  string   `json:"last_serial"`
+	Serials     []string `json:"serials"`
+	Note        string   `json:"note"`
+}
+
+// UpdateSerialStateRequest moves a serial to a new state
+type UpdateSerialStateRequest struct {
+	State       string `json:"state" binding:"required"`
+	CardOwnerID *uint  `json:"card_owner_id,omitempty"` // Registration the card is issued to
+	Note        string `json:"note"`
+}
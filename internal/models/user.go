@@ -7,16 +7,27 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"` // "-" means don't include in JSON
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	Role      string         `json:"role" gorm:"default:'user'"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	Username            string         `json:"username" gorm:"uniqueIndex;not null"`
+	Password            string         `json:"-" gorm:"not null"`  // "-" means don't include in JSON
+	Email               string         `json:"email" gorm:"index"` // Optional, used for password reset
+	Phone               string         `json:"phone"`
+	FirstName           string         `json:"first_name"`
+	LastName            string         `json:"last_name"`
+	IsActive            bool           `json:"is_active" gorm:"default:true"`
+	PendingVerification string         `json:"pending_verification,omitempty"` // Channel awaiting verification; account inactive until then
+	Role                string         `json:"role" gorm:"default:'user'"`
+	OrganizationID      *uint          `json:"organization_id,omitempty" gorm:"index"` // Nil only for super admins
+	BranchID            *uint          `json:"branch_id,omitempty" gorm:"index"`       // Nil for organization-wide users
+	TokenVersion        int            `json:"-" gorm:"not null;default:0"`            // Bumped to revoke all refresh tokens
+	TOTPEnabled         bool           `json:"totp_enabled" gorm:"not null;default:false"`
+	TOTPSecret          string         `json:"-"`
+	TOTPPending         string         `json:"-"`                                        // Secret awaiting confirmation during enrollment
+	TOTPLastStep        int64          `json:"-"`                                        // Last accepted TOTP time step, prevents code replay
+	OIDCSubject         *string        `json:"-" gorm:"column:oidc_subject;uniqueIndex"` // Identity provider subject for SSO accounts
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type LoginRequest struct {
@@ -26,15 +37,56 @@ type LoginRequest struct {
 
 type RegisterRequest struct {
 	Username  string `json:"username" binding:"required,min=3"`
-	Password  string `json:"password" binding:"required,min=6"`
+	Password  string `json:"password" binding:"required"` // Strength checked by the configured password policy
+	Email     string `json:"email" binding:"omitempty,email"`
+	Phone     string `json:"phone" binding:"omitempty,min=9,max=20"`
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
+
+	InvitationCode string `json:"invitation_code"` // Required in invite-only mode
+	CaptchaToken   string `json:"captcha_token"`   // Required when a CAPTCHA provider is configured
+}
+
+// CreateUserRequest creates an account from the command line, with any role
+type CreateUserRequest struct {
+	RegisterRequest
+	Role             string // user, admin or super_admin
+	OrganizationCode string // Defaults to the default organization; super admins have none
+	BranchCode       string // Places the user in a branch, and its organization
 }
 
+// LoginResponse carries tokens, or a challenge token when a second factor is needed
 type LoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
-	User         User   `json:"user"`
+	Token                  string `json:"token,omitempty"`
+	RefreshToken           string `json:"refresh_token,omitempty"`
+	User                   User   `json:"user"`
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"` // role requires 2FA but none is enrolled
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+}
+
+// IsAdmin reports whether the user administers their organization or, as a super admin, all of them
+func (u *User) IsAdmin() bool {
+	return u.Role == "admin" || u.Role == RoleSuperAdmin
+}
+
+// IsSuperAdmin reports whether the user works across organizations
+func (u *User) IsSuperAdmin() bool {
+	return u.Role == RoleSuperAdmin
+}
+
+// Tenant returns the data the user may see
+func (u *User) Tenant() Tenant {
+	tenant := Tenant{All: u.IsSuperAdmin(), BranchID: u.BranchID}
+	if u.OrganizationID != nil {
+		tenant.OrganizationID = *u.OrganizationID
+	}
+	return tenant
+}
+
+// SSO reports whether the account signs in through the identity provider
+func (u *User) SSO() bool {
+	return u.OIDCSubject != nil
 }
 
 type RefreshTokenRequest struct {
@@ -43,11 +95,12 @@ type RefreshTokenRequest struct {
 
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required"`
-	NewPassword     string `json:"new_password" binding:"required,min=6"`
+	NewPassword     string `json:"new_password" binding:"required"` // Strength checked by the configured password policy
 }
 
 type UpdateProfileRequest struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Username  string `json:"username"`
+	Email     string `json:"email" binding:"omitempty,email"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Verification channels for new accounts
+const (
+	VerificationEmail = "email"
+	VerificationPhone = "phone"
+)
+
+// VerificationCode is a short numeric code proving control of an email address or phone
+// Only the SHA-256 hash of the code is stored
+type VerificationCode struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Channel   string    `json:"channel" gorm:"not null"`
+	CodeHash  string    `json:"-" gorm:"not null"`
+	Attempts  int       `json:"-" gorm:"not null;default:0"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VerifyAccountRequest represents the request body for confirming a verification code
+type VerifyAccountRequest struct {
+	Username string `json:"username" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// ResendVerificationRequest represents the request body for requesting a new code
+type ResendVerificationRequest struct {
+	Username string `json:"username" binding:"required"`
+}
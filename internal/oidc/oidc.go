@@ -0,0 +1,208 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Config describes the identity provider and how its claims are read
+type Config struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string // empty for public clients relying on PKCE only
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string // e.g. preferred_username
+	GroupsClaim   string // dotted path, e.g. groups or realm_access.roles
+}
+
+// Identity is the verified subset of ID token claims used for provisioning
+type Identity struct {
+	Subject   string
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+// Flow holds the per-login secrets that must survive the redirect to the IdP
+type Flow struct {
+	State    string
+	Nonce    string
+	Verifier string // PKCE code verifier
+}
+
+// Provider runs the authorization code flow with PKCE against an OIDC identity provider
+type Provider struct {
+	cfg      Config
+	oauth    oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider loads the provider's discovery document
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+	if !contains(scopes, gooidc.ScopeOpenID) {
+		scopes = append([]string{gooidc.ScopeOpenID}, scopes...)
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	return &Provider{
+		cfg: cfg,
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+// NewFlow generates fresh state, nonce and PKCE verifier values
+func NewFlow() (*Flow, error) {
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	return &Flow{State: state, Nonce: nonce, Verifier: oauth2.GenerateVerifier()}, nil
+}
+
+// Encode serializes the flow for storage in a cookie
+func (f *Flow) Encode() string {
+	return f.State + "." + f.Nonce + "." + f.Verifier
+}
+
+// ParseFlow reverses Flow.Encode
+func ParseFlow(value string) (*Flow, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, errors.New("malformed login flow")
+	}
+	return &Flow{State: parts[0], Nonce: parts[1], Verifier: parts[2]}, nil
+}
+
+// AuthCodeURL returns the IdP authorization URL for the flow
+func (p *Provider) AuthCodeURL(flow *Flow) string {
+	return p.oauth.AuthCodeURL(flow.State,
+		gooidc.Nonce(flow.Nonce),
+		oauth2.S256ChallengeOption(flow.Verifier),
+	)
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token
+func (p *Provider) Exchange(ctx context.Context, code string, flow *Flow) (*Identity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("token response has no id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	if idToken.Nonce != flow.Nonce {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("id token claims: %w", err)
+	}
+
+	identity := &Identity{
+		Subject:   idToken.Subject,
+		Username:  stringClaim(claims, p.cfg.UsernameClaim),
+		Email:     stringClaim(claims, "email"),
+		FirstName: stringClaim(claims, "given_name"),
+		LastName:  stringClaim(claims, "family_name"),
+		Groups:    listClaim(claims, p.cfg.GroupsClaim),
+	}
+	// Unverified addresses can't be trusted for password reset or lookups
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		identity.Email = ""
+	}
+	return identity, nil
+}
+
+// lookupClaim follows a dotted path through nested claim objects
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	var value interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func stringClaim(claims map[string]interface{}, path string) string {
+	value, _ := lookupClaim(claims, path).(string)
+	return value
+}
+
+// listClaim accepts either an array of strings or a single string
+func listClaim(claims map[string]interface{}, path string) []string {
+	switch value := lookupClaim(claims, path).(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var list []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
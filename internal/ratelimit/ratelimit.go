@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Policy is a token bucket: Burst tokens at most, refilled at Rate tokens per second
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute builds a policy allowing requests per minute with the given burst
+// A burst of 0 defaults to the per-minute limit
+func PerMinute(requests, burst int) Policy {
+	if burst <= 0 {
+		burst = requests
+	}
+	return Policy{
+		Rate:  float64(requests) / 60,
+		Burst: burst,
+	}
+}
+
+// Enabled reports whether the policy limits anything
+func (p Policy) Enabled() bool {
+	return p.Rate > 0 && p.Burst > 0
+}
+
+// Window is the time an empty bucket takes to refill completely
+func (p Policy) Window() time.Duration {
+	return secondsToDuration(float64(p.Burst) / p.Rate)
+}
+
+// Result describes the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token; zero when allowed
+}
+
+// Store takes tokens from named buckets
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// refill adds the tokens earned since the last take, capped at Burst
+func refill(tokens float64, elapsed time.Duration, policy Policy) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * policy.Rate
+	}
+	return math.Min(tokens, float64(policy.Burst))
+}
+
+// result converts the bucket state after a take into a Result
+func result(allowed bool, tokens float64, policy Policy) Result {
+	res := Result{
+		Allowed:    allowed,
+		Limit:      policy.Burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((float64(policy.Burst) - tokens) / policy.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = secondsToDuration((1 - tokens) / policy.Rate)
+	}
+	return res
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPerMinute(t *testing.T) {
+	tests := []struct {
+		requests, burst int
+		want            Policy
+	}{
+		{requests: 60, burst: 10, want: Policy{Rate: 1, Burst: 10}},
+		{requests: 30, burst: 0, want: Policy{Rate: 0.5, Burst: 30}},
+		{requests: 0, burst: 0, want: Policy{Rate: 0, Burst: 0}},
+	}
+	for _, tt := range tests {
+		got := PerMinute(tt.requests, tt.burst)
+		if got != tt.want {
+			t.Errorf("PerMinute(%d, %d) = %+v, want %+v", tt.requests, tt.burst, got, tt.want)
+		}
+		if got.Enabled() != (tt.requests > 0) {
+			t.Errorf("PerMinute(%d, %d).Enabled() = %v", tt.requests, tt.burst, got.Enabled())
+		}
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	policy := Policy{Rate: 1, Burst: 3} // a token per second, 3 at most
+
+	tests := []struct {
+		name          string
+		takes         int           // takes before the checked one, all at the start
+		advance       time.Duration // time passing before the checked take
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{name: "full bucket", takes: 0, wantAllowed: true, wantRemaining: 2},
+		{name: "last token", takes: 2, wantAllowed: true, wantRemaining: 0},
+		{name: "empty bucket", takes: 3, wantAllowed: false, wantRemaining: 0, wantRetry: time.Second},
+		{name: "refilled one token", takes: 3, advance: time.Second, wantAllowed: true, wantRemaining: 0},
+		{name: "refill is capped at burst", takes: 3, advance: time.Hour, wantAllowed: true, wantRemaining: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			store := NewMemoryStore()
+			store.now = func() time.Time { return now }
+			ctx := context.Background()
+
+			for i := 0; i < tt.takes; i++ {
+				store.Take(ctx, "client", policy)
+			}
+			now = now.Add(tt.advance)
+
+			res, err := store.Take(ctx, "client", policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed != tt.wantAllowed || res.Remaining != tt.wantRemaining || res.RetryAfter != tt.wantRetry {
+				t.Errorf("Take() = %+v, want allowed %v, remaining %d, retry after %v",
+					res, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+			}
+			if res.Limit != policy.Burst {
+				t.Errorf("Limit = %d, want %d", res.Limit, policy.Burst)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreKeysAreSeparate(t *testing.T) {
+	store := NewMemoryStore()
+	policy := Policy{Rate: 1, Burst: 1}
+	ctx := context.Background()
+
+	if res, _ := store.Take(ctx, "a", policy); !res.Allowed {
+		t.Fatal("first take for a was rejected")
+	}
+	if res, _ := store.Take(ctx, "b", policy); !res.Allowed {
+		t.Error("a's take used b's bucket")
+	}
+}
@@ -1,36 +1,148 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"tiger-fasttrack-card/internal/database"
+	"tiger-fasttrack-card/internal/i18n"
 	"tiger-fasttrack-card/internal/models"
-	"errors"
-	
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository struct {
 	DB *database.Database
+
+	tenant *models.Tenant  // Set by ForTenant or AllTenants; tenant-owned data can't be queried without it
+	ctx    context.Context // Set by WithContext; queries are traced and cancelled with it
 }
 
+// errNoTenant fails queries on tenant-owned data made before choosing a tenant
+var errNoTenant = errors.New("repository: tenant-owned data queried without ForTenant or AllTenants")
+
 func New(db *database.Database) *Repository {
 	return &Repository{
 		DB: db,
 	}
 }
 
+// ForTenant returns a repository whose queries on tenant-owned data (branches, stock, card
+// owners, invitations and API clients) only see the tenant's rows
+func (r *Repository) ForTenant(tenant models.Tenant) *Repository {
+	return &Repository{DB: r.DB, tenant: &tenant, ctx: r.ctx}
+}
+
+// AllTenants returns a repository that sees every tenant's rows, for super admins and for
+// lookups already limited some other way, e.g. to the caller's own registrations
+func (r *Repository) AllTenants() *Repository {
+	return r.ForTenant(models.Tenant{All: true})
+}
+
+// WithContext returns a copy of the repository that runs its queries with ctx
+func (r *Repository) WithContext(ctx context.Context) *Repository {
+	return &Repository{DB: r.DB, tenant: r.tenant, ctx: ctx}
+}
+
+// db starts a query, bound to the repository's context when it has one
+func (r *Repository) db() *gorm.DB {
+	if r.ctx == nil {
+		return r.DB.GetDB()
+	}
+	return r.DB.GetDB().WithContext(r.ctx)
+}
+
+// tenantScope limits a query on table to the repository's tenant; branchColumn holds the
+// branch a row belongs to
+func (r *Repository) tenantScope(table, branchColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if r.tenant == nil {
+			db.AddError(errNoTenant)
+			return db
+		}
+		if r.tenant.All {
+			return db
+		}
+		db = db.Where(table+".organization_id = ?", r.tenant.OrganizationID)
+		if r.tenant.BranchID != nil {
+			db = db.Where(branchColumn+" = ?", *r.tenant.BranchID)
+		}
+		return db
+	}
+}
+
+// Organization repository methods
+
+func (r *Repository) CreateOrganization(org *models.Organization) error {
+	return r.db().Create(org).Error
+}
+
+func (r *Repository) GetOrganizations() ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := r.db().Order("code").Find(&orgs).Error
+	return orgs, err
+}
+
+func (r *Repository) GetOrganizationByID(id uint) (*models.Organization, error) {
+	var org models.Organization
+	err := r.db().First(&org, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("organization_not_found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *Repository) GetOrganizationByCode(code string) (*models.Organization, error) {
+	var org models.Organization
+	err := r.db().Where("LOWER(code) = LOWER(?)", code).First(&org).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("organization_not_found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *Repository) UpdateOrganization(org *models.Organization) error {
+	return r.db().Save(org).Error
+}
+
 // Repository methods for cards
 func (r *Repository) GetAllCards() ([]models.Card, error) {
+	return r.GetCards(models.CardFilter{})
+}
+
+// GetCards returns the cards matching filter in display order
+func (r *Repository) GetCards(filter models.CardFilter) ([]models.Card, error) {
+	query := r.db()
+	if filter.Category != "" {
+		query = query.Where("LOWER(category) = LOWER(?)", filter.Category)
+	}
+	if filter.Tier != "" {
+		query = query.Where("LOWER(tier) = LOWER(?)", filter.Tier)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
 	var cards []models.Card
-	err := r.DB.GetDB().Find(&cards).Error
+	err := query.Order("display_order, id").Find(&cards).Error
 	return cards, err
 }
 
 func (r *Repository) GetCardByID(id uint) (*models.Card, error) {
 	var card models.Card
-	err := r.DB.GetDB().First(&card, id).Error
+	err := r.db().First(&card, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("card not found")
+			return nil, i18n.NewError("card_not_found")
 		}
 		return nil, err
 	}
@@ -38,41 +150,455 @@ func (r *Repository) GetCardByID(id uint) (*models.Card, error) {
 }
 
 func (r *Repository) CreateCard(card *models.Card) error {
-	return r.DB.GetDB().Create(card).Error
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		return createCard(tx, card)
+	})
+}
+
+func createCard(tx *gorm.DB, card *models.Card) error {
+	isActive := card.IsActive
+	if err := tx.Create(card).Error; err != nil {
+		return err
+	}
+	// is_active has a database default of true, so GORM skips a false value on insert
+	if !isActive {
+		return tx.Model(card).Update("is_active", false).Error
+	}
+	return nil
+}
+
+// ImportCards creates the imported cards with a new name and updates the cards with the
+// same name, all in one transaction so a failed import changes nothing. Updates leave the
+// stock count, number sequence and, unless the import sets it, is_active alone
+func (r *Repository) ImportCards(imports []models.CardImport) (*models.CardImportResult, error) {
+	result := &models.CardImportResult{}
+	err := r.db().Transaction(func(tx *gorm.DB) error {
+		for _, imported := range imports {
+			card := imported.Card
+			var existing models.Card
+			err := tx.Where("card_name = ?", card.CardName).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := createCard(tx, card); err != nil {
+					return fmt.Errorf("%s: failed to create card: %w", card.CardName, err)
+				}
+				result.Created++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", card.CardName, err)
+			}
+
+			card.ID = existing.ID
+			card.CreatedAt = existing.CreatedAt
+			omit := []string{"NextSequence", "CardQuantity"}
+			if !imported.SetsActive {
+				omit = append(omit, "IsActive")
+				card.IsActive = existing.IsActive
+			}
+			if err := tx.Omit(omit...).Save(card).Error; err != nil {
+				return fmt.Errorf("%s: failed to update card: %w", card.CardName, err)
+			}
+			result.Updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 func (r *Repository) UpdateCard(card *models.Card) error {
-	return r.DB.GetDB().Save(card).Error
+	// next_sequence only moves through AllocateCardSequences and card_quantity is
+	// derived from the stock ledger
+	return r.db().Omit("NextSequence", "CardQuantity").Save(card).Error
+}
+
+// AllocateCardSequences atomically reserves count sequences of the card's numbering
+// scheme and returns the first one; the returned card only has its scheme loaded
+func (r *Repository) AllocateCardSequences(cardID uint, count int64) (*models.Card, int64, error) {
+	return allocateCardSequences(r.db(), cardID, count)
+}
+
+func allocateCardSequences(db *gorm.DB, cardID uint, count int64) (*models.Card, int64, error) {
+	var card models.Card
+	result := db.Raw(`UPDATE cards SET next_sequence = next_sequence + ?
+		WHERE id = ? AND deleted_at IS NULL
+		RETURNING id, number_prefix, number_length, check_digit, next_sequence`, count, cardID).Scan(&card)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, 0, i18n.NewError("card_not_found")
+	}
+	return &card, card.NextSequence - count, nil
+}
+
+// CreateCardNumberRange reserves rng.Count sequences of the card and stores the range;
+// fill sets the range's numbers from the card's scheme before it is saved
+func (r *Repository) CreateCardNumberRange(rng *models.CardNumberRange, fill func(card *models.Card) error) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		card, first, err := allocateCardSequences(tx, rng.CardID, int64(rng.Count))
+		if err != nil {
+			return err
+		}
+		rng.FirstSequence = first
+		rng.LastSequence = first + int64(rng.Count) - 1
+		if err := fill(card); err != nil {
+			return err
+		}
+		return tx.Create(rng).Error
+	})
+}
+
+func (r *Repository) GetCardNumberRanges(cardID uint) ([]models.CardNumberRange, error) {
+	var ranges []models.CardNumberRange
+	err := r.db().Where("card_id = ?", cardID).Order("first_sequence").Find(&ranges).Error
+	return ranges, err
+}
+
+func (r *Repository) GetCardNumberRange(cardID, id uint) (*models.CardNumberRange, error) {
+	var rng models.CardNumberRange
+	err := r.db().Where("card_id = ?", cardID).First(&rng, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("card_number_range_not_found")
+		}
+		return nil, err
+	}
+	return &rng, nil
 }
 
 func (r *Repository) DeleteCard(id uint) error {
-	return r.DB.GetDB().Delete(&models.Card{}, id).Error
+	return r.db().Delete(&models.Card{}, id).Error
 }
 
 func (r *Repository) GetCardByName(cardName string) (*models.Card, error) {
 	var card models.Card
-	err := r.DB.GetDB().Where("card_name = ?", cardName).First(&card).Error
+	err := r.db().Where("card_name = ?", cardName).First(&card).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("card not found")
+			return nil, i18n.NewError("card_not_found")
 		}
 		return nil, err
 	}
 	return &card, nil
 }
 
+// Stock repository methods
+
+func (r *Repository) CreateBranch(branch *models.Branch) error {
+	return r.db().Create(branch).Error
+}
+
+func (r *Repository) GetBranches() ([]models.Branch, error) {
+	var branches []models.Branch
+	err := r.db().Scopes(r.tenantScope("branches", "branches.id")).Order("code").Find(&branches).Error
+	return branches, err
+}
+
+func (r *Repository) GetBranchByID(id uint) (*models.Branch, error) {
+	var branch models.Branch
+	err := r.db().Scopes(r.tenantScope("branches", "branches.id")).First(&branch, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("branch_not_found")
+		}
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *Repository) GetBranchByCode(code string) (*models.Branch, error) {
+	var branch models.Branch
+	err := r.db().Where("LOWER(code) = LOWER(?)", code).First(&branch).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("branch_not_found")
+		}
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *Repository) UpdateBranch(branch *models.Branch) error {
+	return r.db().Save(branch).Error
+}
+
+// ReceiveCardBatch stores a batch with one in-stock serial per entry of serials and
+// records the receipt of each in the ledger
+func (r *Repository) ReceiveCardBatch(batch *models.CardBatch, serials []string) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		var existing []string
+		err := tx.Model(&models.CardSerial{}).
+			Where("card_id = ? AND serial IN ?", batch.CardID, serials).
+			Limit(1).Pluck("serial", &existing).Error
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return i18n.NewError("serial_already_received", existing[0])
+		}
+
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		rows := make([]models.CardSerial, len(serials))
+		for i, serial := range serials {
+			rows[i] = models.CardSerial{
+				OrganizationID: batch.OrganizationID,
+				CardID:         batch.CardID,
+				Serial:         serial,
+				BatchID:        batch.ID,
+				State:          models.SerialInStock,
+				BranchID:       batch.BranchID,
+			}
+		}
+		if err := tx.CreateInBatches(rows, 1000).Error; err != nil {
+			return err
+		}
+
+		movements := make([]models.StockMovement, len(rows))
+		for i, row := range rows {
+			movements[i] = models.StockMovement{
+				CardID:     row.CardID,
+				SerialID:   row.ID,
+				Serial:     row.Serial,
+				ToState:    row.State,
+				ToBranchID: row.BranchID,
+				Note:       batch.Note,
+				UserID:     batch.ReceivedByID,
+			}
+		}
+		if err := tx.CreateInBatches(movements, 1000).Error; err != nil {
+			return err
+		}
+		return refreshCardQuantity(tx, batch.CardID)
+	})
+}
+
+// MoveCardSerials moves the card's serials as described by move and records a ledger
+// entry for each; an empty ToState keeps the state and a nil ToBranchID keeps the branch.
+// check is called for every serial, locked, before anything changes
+func (r *Repository) MoveCardSerials(cardID uint, serials []string, move models.StockMovement, check func(serial *models.CardSerial) error) ([]models.CardSerial, error) {
+	var rows []models.CardSerial
+	err := r.db().Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(r.tenantScope("card_serials", "card_serials.branch_id")).
+			Where("card_id = ? AND serial IN ?", cardID, serials).
+			Order("serial").Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) != len(serials) {
+			found := make(map[string]bool, len(rows))
+			for _, row := range rows {
+				found[row.Serial] = true
+			}
+			for _, serial := range serials {
+				if !found[serial] {
+					return i18n.NewError("serial_not_found", serial)
+				}
+			}
+		}
+
+		ids := make([]uint, len(rows))
+		movements := make([]models.StockMovement, len(rows))
+		for i := range rows {
+			if err := check(&rows[i]); err != nil {
+				return err
+			}
+			entry := move
+			entry.CardID = cardID
+			entry.SerialID = rows[i].ID
+			entry.Serial = rows[i].Serial
+			entry.FromState = rows[i].State
+			entry.FromBranchID = rows[i].BranchID
+			if entry.ToState == "" {
+				entry.ToState = rows[i].State
+			}
+			if entry.ToBranchID == nil {
+				entry.ToBranchID = rows[i].BranchID
+			}
+			if entry.CardOwnerID == nil {
+				entry.CardOwnerID = rows[i].CardOwnerID
+			}
+			rows[i].State = entry.ToState
+			rows[i].BranchID = entry.ToBranchID
+			rows[i].CardOwnerID = entry.CardOwnerID
+			ids[i] = rows[i].ID
+			movements[i] = entry
+		}
+
+		updates := map[string]interface{}{"updated_at": time.Now()}
+		if move.ToState != "" {
+			updates["state"] = move.ToState
+		}
+		if move.ToBranchID != nil {
+			updates["branch_id"] = *move.ToBranchID
+		}
+		if move.CardOwnerID != nil {
+			updates["card_owner_id"] = *move.CardOwnerID
+		}
+		if err := tx.Model(&models.CardSerial{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.CreateInBatches(movements, 1000).Error; err != nil {
+			return err
+		}
+		return refreshCardQuantity(tx, cardID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// refreshCardQuantity sets the card's quantity to its number of in-stock serials
+func refreshCardQuantity(tx *gorm.DB, cardID uint) error {
+	return tx.Exec(`UPDATE cards SET card_quantity =
+		(SELECT COUNT(*) FROM card_serials WHERE card_id = ? AND state = ?)
+		WHERE id = ?`, cardID, models.SerialInStock, cardID).Error
+}
+
+// GetCardBatches returns the batches of a card, or of all cards when cardID is 0, newest first
+func (r *Repository) GetCardBatches(cardID uint) ([]models.CardBatch, error) {
+	query := r.db().Scopes(r.tenantScope("card_batches", "card_batches.branch_id"))
+	if cardID != 0 {
+		query = query.Where("card_id = ?", cardID)
+	}
+	var batches []models.CardBatch
+	err := query.Order("id DESC").Find(&batches).Error
+	return batches, err
+}
+
+// GetCardSerials returns up to limit serials matching filter in serial order
+func (r *Repository) GetCardSerials(filter models.StockFilter, limit int) ([]models.CardSerial, error) {
+	var serials []models.CardSerial
+	err := stockQuery(r.db().Scopes(r.tenantScope("card_serials", "card_serials.branch_id")), filter).Order("serial").Limit(limit).Find(&serials).Error
+	return serials, err
+}
+
+func (r *Repository) GetCardSerial(cardID uint, serial string) (*models.CardSerial, error) {
+	var row models.CardSerial
+	err := r.db().Scopes(r.tenantScope("card_serials", "card_serials.branch_id")).
+		Where("card_id = ? AND serial = ?", cardID, serial).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("serial_not_found", serial)
+		}
+		return nil, err
+	}
+	return &row, nil
+}
+
+// GetStockMovements returns the ledger entries of a serial, oldest first
+func (r *Repository) GetStockMovements(serialID uint) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	err := r.db().Where("serial_id = ?", serialID).Order("id").Find(&movements).Error
+	return movements, err
+}
+
+// GetStockLevels counts the serials matching filter per card, branch and state
+func (r *Repository) GetStockLevels(filter models.StockFilter) ([]models.StockLevel, error) {
+	var levels []models.StockLevel
+	query := r.db().Model(&models.CardSerial{}).Scopes(r.tenantScope("card_serials", "card_serials.branch_id"))
+	err := stockQuery(query, filter).
+		Select("card_id, branch_id, state, COUNT(*) AS count").
+		Group("card_id, branch_id, state").
+		Order("card_id, branch_id, state").
+		Scan(&levels).Error
+	return levels, err
+}
+
+func stockQuery(query *gorm.DB, filter models.StockFilter) *gorm.DB {
+	if filter.CardID != 0 {
+		query = query.Where("card_id = ?", filter.CardID)
+	}
+	if filter.BranchID != nil {
+		query = query.Where("branch_id = ?", *filter.BranchID)
+	}
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	return query
+}
+
 // User repository methods
 
 func (r *Repository) CreateUser(user *models.User) error {
-	return r.DB.GetDB().Create(user).Error
+	return r.db().Create(user).Error
+}
+
+// ErrInvitationUnavailable is returned by RegisterUser when the invitation can't be consumed
+var ErrInvitationUnavailable = i18n.NewError("invitation_unavailable")
+
+// RegisterUser creates a self-registered user, consuming the invitation when invitationID
+// is set; fails if the invitation was used, revoked or expired in the meantime
+func (r *Repository) RegisterUser(user *models.User, invitationID uint) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if invitationID != 0 {
+			result := tx.Model(&models.Invitation{}).
+				Where("id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", invitationID, now).
+				Update("used_at", now)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != 1 {
+				return ErrInvitationUnavailable
+			}
+		}
+
+		isActive := user.IsActive
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		// is_active has a database default of true, so GORM skips a false value on insert
+		if !isActive {
+			if err := tx.Model(user).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+
+		if invitationID != 0 {
+			return tx.Model(&models.Invitation{}).Where("id = ?", invitationID).Update("used_by_id", user.ID).Error
+		}
+		return nil
+	})
 }
 
 func (r *Repository) GetUserByUsername(username string) (*models.User, error) {
 	var user models.User
-	err := r.DB.GetDB().Where("username = ?", username).First(&user).Error
+	err := r.db().Where("username = ?", username).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("user_not_found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *Repository) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	err := r.db().Where("LOWER(email) = LOWER(?)", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, i18n.NewError("user_not_found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *Repository) GetUserByOIDCSubject(subject string) (*models.User, error) {
+	var user models.User
+	err := r.db().Where("oidc_subject = ?", subject).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("user_not_found")
 		}
 		return nil, err
 	}
@@ -81,10 +607,10 @@ func (r *Repository) GetUserByUsername(username string) (*models.User, error) {
 
 func (r *Repository) GetUserByID(id uint) (*models.User, error) {
 	var user models.User
-	err := r.DB.GetDB().First(&user, id).Error
+	err := r.db().First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, i18n.NewError("user_not_found")
 		}
 		return nil, err
 	}
@@ -92,25 +618,182 @@ func (r *Repository) GetUserByID(id uint) (*models.User, error) {
 }
 
 func (r *Repository) UpdateUser(user *models.User) error {
-	return r.DB.GetDB().Save(user).Error
+	return r.db().Save(user).Error
 }
 
 func (r *Repository) DeleteUser(id uint) error {
-	return r.DB.GetDB().Delete(&models.User{}, id).Error
+	return r.db().Delete(&models.User{}, id).Error
+}
+
+// Password history repository methods
+
+func (r *Repository) CreatePasswordHistory(entry *models.PasswordHistory) error {
+	return r.db().Create(entry).Error
+}
+
+// GetRecentPasswordHistory returns the newest password hashes for a user, newest first
+func (r *Repository) GetRecentPasswordHistory(userID uint, limit int) ([]models.PasswordHistory, error) {
+	var entries []models.PasswordHistory
+	err := r.db().Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// PrunePasswordHistory keeps only the newest keep entries for a user
+func (r *Repository) PrunePasswordHistory(userID uint, keep int) error {
+	db := r.db()
+	keepIDs := db.Model(&models.PasswordHistory{}).Select("id").Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(keep)
+	return db.Where("user_id = ? AND id NOT IN (?)", userID, keepIDs).Delete(&models.PasswordHistory{}).Error
+}
+
+// Password reset repository methods
+
+func (r *Repository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	return r.db().Create(token).Error
+}
+
+func (r *Repository) GetPasswordResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db().Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("reset_token_not_found")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumePasswordResetToken marks an unused token as used
+// Returns false if another request used the token first
+func (r *Repository) ConsumePasswordResetToken(id uint) (bool, error) {
+	result := r.db().Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidatePasswordResetTokens marks every unused token of a user as used
+func (r *Repository) InvalidatePasswordResetTokens(userID uint) error {
+	return r.db().Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+// Recovery code repository methods
+
+// ReplaceRecoveryCodes deletes a user's recovery codes and stores the new hashes
+func (r *Repository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode marks a matching unused code as used
+// Returns false if no unused code matched
+func (r *Repository) ConsumeRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db().Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *Repository) DeleteRecoveryCodes(userID uint) error {
+	return r.db().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+// API client repository methods
+
+// CreateAPIClient stores a client together with its service user
+func (r *Repository) CreateAPIClient(client *models.APIClient, serviceUser *models.User) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(serviceUser).Error; err != nil {
+			return err
+		}
+		client.UserID = serviceUser.ID
+		return tx.Create(client).Error
+	})
+}
+
+func (r *Repository) GetAPIClientByID(id uint) (*models.APIClient, error) {
+	var client models.APIClient
+	err := r.db().Scopes(r.apiClientScope).First(&client, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("api_client_not_found")
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *Repository) GetAPIClientByClientID(clientID string) (*models.APIClient, error) {
+	var client models.APIClient
+	err := r.db().Where("client_id = ?", clientID).First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("api_client_not_found")
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *Repository) GetAllAPIClients() ([]models.APIClient, error) {
+	var clients []models.APIClient
+	err := r.db().Scopes(r.apiClientScope).Order("id").Find(&clients).Error
+	return clients, err
+}
+
+// apiClientScope limits API clients to those whose service user is in the tenant
+func (r *Repository) apiClientScope(db *gorm.DB) *gorm.DB {
+	if r.tenant == nil {
+		db.AddError(errNoTenant)
+		return db
+	}
+	if r.tenant.All {
+		return db
+	}
+	serviceUsers := r.db().Model(&models.User{}).Select("id").Scopes(r.tenantScope("users", "users.branch_id"))
+	return db.Where("user_id IN (?)", serviceUsers)
+}
+
+func (r *Repository) UpdateAPIClient(client *models.APIClient) error {
+	return r.db().Save(client).Error
+}
+
+// RevokeAPIClient deactivates a client and its service user
+func (r *Repository) RevokeAPIClient(client *models.APIClient) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", client.UserID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(client).Update("is_active", false).Error
+	})
+}
+
+func (r *Repository) TouchAPIClient(id uint) error {
+	return r.db().Model(&models.APIClient{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
 }
 
 // CardOwner repository methods
 
 func (r *Repository) CreateCardOwner(owner *models.CardOwner) error {
-	return r.DB.GetDB().Create(owner).Error
+	return r.db().Create(owner).Error
 }
 
 func (r *Repository) GetCardOwnerByID(id uint) (*models.CardOwner, error) {
 	var owner models.CardOwner
-	err := r.DB.GetDB().Preload("User").Preload("Card").First(&owner, id).Error
+	err := r.db().Scopes(r.tenantScope("card_owners", "card_owners.branch_id")).Preload("User").Preload("Card").Preload("Person").First(&owner, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("card owner not found")
+			return nil, i18n.NewError("card_owner_not_found")
 		}
 		return nil, err
 	}
@@ -119,10 +802,10 @@ func (r *Repository) GetCardOwnerByID(id uint) (*models.CardOwner, error) {
 
 func (r *Repository) GetCardOwnerByUserID(userID uint) (*models.CardOwner, error) {
 	var owner models.CardOwner
-	err := r.DB.GetDB().Preload("User").Preload("Card").Where("user_id = ?", userID).First(&owner).Error
+	err := r.db().Scopes(r.tenantScope("card_owners", "card_owners.branch_id")).Preload("User").Preload("Card").Preload("Person").Where("user_id = ?", userID).First(&owner).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("card owner not found")
+			return nil, i18n.NewError("card_owner_not_found")
 		}
 		return nil, err
 	}
@@ -131,7 +814,7 @@ func (r *Repository) GetCardOwnerByUserID(userID uint) (*models.CardOwner, error
 
 func (r *Repository) GetCardOwnersByUserID(userID uint) ([]models.CardOwner, error) {
 	var owners []models.CardOwner
-	err := r.DB.GetDB().Preload("User").Preload("Card").Where("user_id = ?", userID).Find(&owners).Error
+	err := r.db().Scopes(r.tenantScope("card_owners", "card_owners.branch_id")).Preload("User").Preload("Card").Preload("Person").Where("user_id = ?", userID).Find(&owners).Error
 	if err != nil {
 		return nil, err
 	}
@@ -140,10 +823,10 @@ func (r *Repository) GetCardOwnersByUserID(userID uint) ([]models.CardOwner, err
 
 func (r *Repository) GetCardOwnerByCardNumberAndCardID(cardNumber string, cardID uint) (*models.CardOwner, error) {
 	var owner models.CardOwner
-	err := r.DB.GetDB().Preload("User").Preload("Card").Where("card_number = ? AND card_id = ?", cardNumber, cardID).First(&owner).Error
+	err := r.db().Preload("User").Preload("Card").Preload("Person").Where("card_number = ? AND card_id = ?", cardNumber, cardID).First(&owner).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("card owner not found")
+			return nil, i18n.NewError("card_owner_not_found")
 		}
 		return nil, err
 	}
@@ -152,10 +835,12 @@ func (r *Repository) GetCardOwnerByCardNumberAndCardID(cardNumber string, cardID
 
 func (r *Repository) GetCardOwnerByIDCard(idCard string) (*models.CardOwner, error) {
 	var owner models.CardOwner
-	err := r.DB.GetDB().Preload("User").Preload("Card").Where("id_card = ?", idCard).First(&owner).Error
+	err := r.db().Scopes(r.tenantScope("card_owners", "card_owners.branch_id")).Preload("User").Preload("Card").Preload("Person").
+		Joins("JOIN people ON people.id = card_owners.person_id").
+		Where("people.document_number = ?", models.NormalizeDocumentNumber(idCard)).First(&owner).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("card owner not found")
+			return nil, i18n.NewError("card_owner_not_found")
 		}
 		return nil, err
 	}
@@ -164,14 +849,203 @@ func (r *Repository) GetCardOwnerByIDCard(idCard string) (*models.CardOwner, err
 
 func (r *Repository) GetAllCardOwners() ([]models.CardOwner, error) {
 	var owners []models.CardOwner
-	err := r.DB.GetDB().Preload("User").Preload("Card").Find(&owners).Error
+	err := r.db().Scopes(r.tenantScope("card_owners", "card_owners.branch_id")).Preload("User").Preload("Card").Preload("Person").Find(&owners).Error
 	return owners, err
 }
 
 func (r *Repository) UpdateCardOwner(owner *models.CardOwner) error {
-	return r.DB.GetDB().Save(owner).Error
+	return r.db().Save(owner).Error
 }
 
 func (r *Repository) DeleteCardOwner(id uint) error {
-	return r.DB.GetDB().Delete(&models.CardOwner{}, id).Error
+	return r.db().Delete(&models.CardOwner{}, id).Error
+}
+
+// Person repository methods
+
+func (r *Repository) GetPersonByID(id uint) (*models.Person, error) {
+	var person models.Person
+	err := r.db().Preload("Cards", r.tenantScope("card_owners", "card_owners.branch_id")).
+		Preload("Cards.Card").Preload("Cards.User").First(&person, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("person_not_found")
+		}
+		return nil, err
+	}
+	return &person, nil
+}
+
+// GetPersonByDocument looks a person up by a normalized identity document
+func (r *Repository) GetPersonByDocument(doc models.IdentityDocument) (*models.Person, error) {
+	var person models.Person
+	err := r.db().Preload("Cards", r.tenantScope("card_owners", "card_owners.branch_id")).
+		Preload("Cards.Card").Preload("Cards.User").
+		Where("document_type = ? AND issuing_country = ? AND document_number = ?", doc.Type, doc.Country, doc.Number).First(&person).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("person_not_found")
+		}
+		return nil, err
+	}
+	return &person, nil
+}
+
+// GetAllPeople returns the people holding at least one card registered in the tenant
+func (r *Repository) GetAllPeople() ([]models.Person, error) {
+	if r.tenant == nil {
+		return nil, errNoTenant
+	}
+
+	query := r.db()
+	if !r.tenant.All {
+		registered := r.db().Model(&models.CardOwner{}).Select("person_id").
+			Scopes(r.tenantScope("card_owners", "card_owners.branch_id"))
+		query = query.Where("id IN (?)", registered)
+	}
+
+	var people []models.Person
+	err := query.Order("id").Find(&people).Error
+	return people, err
+}
+
+// FindOrCreatePerson returns the person holding the given (normalized) identity document,
+// creating them if needed; a non-empty phone number replaces the stored one so the latest
+// contact details win
+func (r *Repository) FindOrCreatePerson(doc models.IdentityDocument, phoneNumber string) (*models.Person, error) {
+	db := r.db()
+	query := db.Where("document_type = ? AND issuing_country = ? AND document_number = ?", doc.Type, doc.Country, doc.Number).
+		Session(&gorm.Session{}) // Reused for the retry below
+
+	var person models.Person
+	err := query.First(&person).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		person = models.Person{
+			DocumentType:   doc.Type,
+			IssuingCountry: doc.Country,
+			DocumentNumber: doc.Number,
+			PhoneNumber:    phoneNumber,
+		}
+		if err = db.Create(&person).Error; err != nil {
+			// Lost a race with a concurrent registration for the same person
+			person = models.Person{}
+			err = query.First(&person).Error
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if phoneNumber != "" && person.PhoneNumber != phoneNumber {
+		person.PhoneNumber = phoneNumber
+		if err := db.Model(&person).Update("phone_number", phoneNumber).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &person, nil
+}
+
+func (r *Repository) UpdatePerson(person *models.Person) error {
+	return r.db().Omit("Cards").Save(person).Error
+}
+
+// Invitation repository methods
+func (r *Repository) CreateInvitation(invitation *models.Invitation) error {
+	return r.db().Create(invitation).Error
+}
+
+func (r *Repository) GetInvitationByID(id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db().Scopes(r.tenantScope("invitations", "invitations.branch_id")).First(&invitation, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("invitation_not_found")
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *Repository) GetInvitationByHash(codeHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db().Where("code_hash = ?", codeHash).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("invitation_not_found")
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *Repository) GetAllInvitations() ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	err := r.db().Scopes(r.tenantScope("invitations", "invitations.branch_id")).Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *Repository) RevokeInvitation(id uint) error {
+	return r.db().Model(&models.Invitation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Verification code repository methods
+
+// ReplaceVerificationCode stores a new code for the user, discarding earlier ones
+// Wrong attempts against a code that hasn't expired carry over, so asking for a new code
+// doesn't reset the attempt limit
+func (r *Repository) ReplaceVerificationCode(code *models.VerificationCode) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		var previous models.VerificationCode
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND expires_at > ?", code.UserID, time.Now()).
+			Order("attempts DESC").
+			First(&previous).Error
+		switch {
+		case err == nil:
+			code.Attempts = previous.Attempts
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", code.UserID).Delete(&models.VerificationCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+}
+
+func (r *Repository) GetVerificationCode(userID uint) (*models.VerificationCode, error) {
+	var code models.VerificationCode
+	err := r.db().Where("user_id = ?", userID).Order("created_at DESC").First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("verification_code_not_found")
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// ConsumeVerificationAttempt counts a guess at a verification code in one conditional
+// update, so parallel guesses can't get past the limit
+// Returns false if the code has no attempts left
+func (r *Repository) ConsumeVerificationAttempt(id uint, maxAttempts int) (bool, error) {
+	result := r.db().Model(&models.VerificationCode{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ActivateVerifiedUser activates a user whose verification completed and removes their codes
+func (r *Repository) ActivateVerifiedUser(userID uint) error {
+	return r.db().Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"is_active": true, "pending_verification": ""}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.VerificationCode{}).Error
+	})
 }
@@ -1,70 +1,169 @@
 package routes
 
 import (
+	"tiger-fasttrack-card/internal/config"
 	"tiger-fasttrack-card/internal/handlers"
+	"tiger-fasttrack-card/internal/metrics"
 	"tiger-fasttrack-card/internal/middleware"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/ratelimit"
+	"tiger-fasttrack-card/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Setup(router *gin.Engine, h *handlers.Handler, jwtSecret string) {
-	// Health check endpoint
+func Setup(router *gin.Engine, h *handlers.Handler, cfg *config.Config, jwtManager *utils.JWTManager, limiter ratelimit.Store) {
+	// Rate limit policies per route group; limiter is nil when rate limiting is disabled
+	authLimit := middleware.RateLimit(limiter, "auth", rateLimitPolicy(cfg.RateLimit.Auth))
+	validateLimit := middleware.RateLimit(limiter, "validate", rateLimitPolicy(cfg.RateLimit.Validate))
+	apiLimit := middleware.RateLimitReadWrite(limiter, "api",
+		rateLimitPolicy(cfg.RateLimit.Read), rateLimitPolicy(cfg.RateLimit.Write))
+
+	// Request body limits, applied after authentication so rejected requests aren't buffered
+	body := middleware.BodyLimit(int64(cfg.Server.MaxBodyBytes), cfg.Server.MaxJSONDepth)
+	uploadBody := middleware.BodyLimit(int64(cfg.Server.MaxUploadBodyBytes), cfg.Server.MaxJSONDepth)
+
+	// Health check endpoints; /livez and /readyz are the probes, /health is kept for existing monitors
+	router.GET("/livez", h.Livez)
+	router.GET("/readyz", h.Readyz)
 	router.GET("/health", h.HealthCheck)
 
+	// Prometheus metrics, unless they are served on their own port
+	if cfg.Metrics.Enabled && cfg.Metrics.Port == "" {
+		SetupMetrics(router, cfg)
+	}
+
+	// Public keys for partners verifying our tokens
+	router.GET("/.well-known/jwks.json", h.JWKS)
+
 	// API v1 group
 	v1 := router.Group("/api/v1")
 	{
 		// Auth routes (public)
 		auth := v1.Group("/auth")
+		auth.Use(authLimit, body)
 		{
 			auth.POST("/register", h.Register)
+			auth.POST("/verify", h.VerifyAccount)
+			auth.POST("/verify/resend", h.ResendVerification)
 			auth.POST("/login", h.Login)
 			auth.POST("/refresh", h.RefreshToken)
+			auth.POST("/forgot-password", h.ForgotPassword)
+			auth.POST("/reset-password", h.ResetPassword)
+			auth.POST("/2fa/verify", h.VerifyTwoFactor)          // Second login step (challenge token + code)
+			auth.POST("/2fa/setup", h.ChallengeTwoFactorSetup)   // Enrollment when the role requires 2FA
+			auth.POST("/2fa/enable", h.ChallengeTwoFactorEnable) // Confirm enrollment and log in
+			auth.GET("/oidc/login", h.OIDCLogin)                 // Redirect to the staff identity provider
+			auth.GET("/oidc/callback", h.OIDCCallback)           // Identity provider redirects back here
 		}
 
+		// OAuth2 client-credentials token endpoint for partner API clients
+		v1.POST("/oauth/token", authLimit, body, h.ClientToken)
+
 		// User routes
 		users := v1.Group("/users")
-		users.Use(middleware.AuthMiddleware(jwtSecret))
+		users.Use(middleware.AuthMiddleware(jwtManager), apiLimit, body)
 		{
 			users.GET("/profile", h.GetProfile)
 			users.PUT("/profile", h.UpdateProfile)
 			users.POST("/change-password", h.ChangePassword)
+			users.POST("/2fa/setup", h.SetupTwoFactor)
+			users.POST("/2fa/enable", h.EnableTwoFactor)
+			users.POST("/2fa/disable", h.DisableTwoFactor)
+			users.POST("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
 		}
 
 		// Cards routes (protected)
 		cards := v1.Group("/cards")
-		cards.Use(middleware.AuthMiddleware(jwtSecret))
+		cards.Use(middleware.AuthMiddleware(jwtManager), apiLimit, uploadBody) // Card images
 		{
 			cards.GET("", h.GetCards)
 			cards.GET("/:id", h.GetCardByID)
 			cards.POST("", h.CreateCard)
 			cards.PUT("/:id", h.UpdateCard)
 			cards.DELETE("/:id", h.DeleteCard)
+			cards.POST("/:id/number-ranges", h.CreateCardNumberRange) // Pre-generate numbers for printed stock
+			cards.GET("/:id/number-ranges", h.GetCardNumberRanges)
+			cards.GET("/:id/number-ranges/:rangeId", h.GetCardNumberRange) // ?format=csv for the printer
 		}
 
 		// Card Owner routes (protected)
+		// Partner endpoints also accept API client tokens carrying the listed scope
+		cardOwnersRead := middleware.AuthMiddleware(jwtManager, models.ScopeCardOwnersRead)
+		cardOwnersRegister := middleware.AuthMiddleware(jwtManager, models.ScopeCardOwnersRegister)
+		userOnly := middleware.AuthMiddleware(jwtManager)
+
 		cardOwners := v1.Group("/card-owners")
-		cardOwners.Use(middleware.AuthMiddleware(jwtSecret))
 		{
-			cardOwners.POST("/register", h.RegisterCardOwner)          // Register single card
-			cardOwners.POST("/register-multiple", h.RegisterMultipleCards) // Register multiple cards
-			cardOwners.GET("/profile", h.GetCardOwnerProfile)          // Gets first card (backward compatibility)
-			cardOwners.GET("/profiles", h.GetCardOwnerProfiles)        // Gets all cards for user
-			cardOwners.PUT("/:id", h.UpdateCardOwner)                  // Update specific card owner by ID
-			cardOwners.DELETE("/:id", h.DeleteCardOwner)               // Delete specific card owner by ID
-			cardOwners.GET("/all", h.GetAllCardOwners)                 // Admin only
-			
+			cardOwners.POST("/register", cardOwnersRegister, apiLimit, body, h.RegisterCardOwner)                    // Register single card
+			cardOwners.POST("/register-multiple", cardOwnersRegister, apiLimit, uploadBody, h.RegisterMultipleCards) // Register multiple cards
+			cardOwners.GET("/profile", userOnly, apiLimit, h.GetCardOwnerProfile)                                    // Gets first card (backward compatibility)
+			cardOwners.GET("/profiles", userOnly, apiLimit, h.GetCardOwnerProfiles)                                  // Gets all cards for user
+			cardOwners.PUT("/:id", userOnly, apiLimit, body, h.UpdateCardOwner)                                      // Update specific card owner by ID
+			cardOwners.DELETE("/:id", userOnly, apiLimit, h.DeleteCardOwner)                                         // Delete specific card owner by ID
+			cardOwners.GET("/all", userOnly, apiLimit, h.GetAllCardOwners)                                           // Admin only
+
 			// New API endpoints
-			cardOwners.POST("/validate-duplicate", h.ValidateDuplicateCardRegistration) // Validate duplicate registration
-			cardOwners.GET("/search/by-card", h.SearchCardOwnersByCardNameAndNumber)    // Search by card name and number
-			cardOwners.GET("/search/by-owner", h.SearchCardOwnersByIDCardOrPhone)       // Search by ID card or phone
+			cardOwners.POST("/validate-duplicate", cardOwnersRead, apiLimit, validateLimit, body, h.ValidateDuplicateCardRegistration) // Validate duplicate registration
+			cardOwners.GET("/search/by-card", cardOwnersRead, apiLimit, h.SearchCardOwnersByCardNameAndNumber)                         // Search by card name and number
+			cardOwners.GET("/search/by-owner", cardOwnersRead, apiLimit, h.SearchCardOwnersByIDCardOrPhone)                            // Search by document number or phone
+		}
+
+		// People (card holders) routes
+		people := v1.Group("/people")
+		{
+			people.GET("", userOnly, apiLimit, h.GetPeople)                           // Admin only
+			people.GET("/search", cardOwnersRead, apiLimit, h.SearchPersonByDocument) // Exact identity document lookup
+			people.GET("/:id", cardOwnersRead, apiLimit, h.GetPerson)                 // Person with their cards
+			people.PUT("/:id", userOnly, apiLimit, body, h.UpdatePerson)              // Update contact details (admin only)
+		}
+
+		// Admin routes (protected, role checked in service)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(jwtManager), apiLimit, body)
+		{
+			admin.POST("/users/:id/unlock", h.UnlockUser)
+			admin.PUT("/users/:id/tenant", h.AssignUserTenant)
+			admin.POST("/invitations", h.CreateInvitation)
+			admin.GET("/invitations", h.GetInvitations)
+			admin.DELETE("/invitations/:id", h.RevokeInvitation)
+			admin.POST("/api-clients", h.CreateAPIClient)
+			admin.GET("/api-clients", h.GetAPIClients)
+			admin.POST("/api-clients/:id/rotate-secret", h.RotateAPIClientSecret)
+			admin.DELETE("/api-clients/:id", h.RevokeAPIClient)
+
+			// Tenants (organizations are super admin only)
+			admin.POST("/organizations", h.CreateOrganization)
+			admin.GET("/organizations", h.GetOrganizations)
+			admin.PUT("/organizations/:id", h.UpdateOrganization)
+			admin.POST("/branches", h.CreateBranch)
+			admin.GET("/branches", h.GetBranches)
+			admin.PUT("/branches/:id", h.UpdateBranch)
+
+			// Physical card stock
+			admin.POST("/stock/batches", h.ReceiveCardBatch)                                  // Receive a serial range or a number range
+			admin.GET("/stock/batches", h.GetCardBatches)                                     // ?card_id=
+			admin.POST("/stock/allocations", h.AllocateStock)                                 // Move in-stock serials to a branch
+			admin.GET("/stock/summary", h.GetStockSummary)                                    // Counts per card, branch and state
+			admin.GET("/stock/cards/:cardId/serials", h.GetCardSerials)                       // ?state=&branch_id=&limit=
+			admin.GET("/stock/cards/:cardId/serials/:serial", h.GetCardSerial)                // Serial with its ledger history
+			admin.POST("/stock/cards/:cardId/serials/:serial/state", h.UpdateCardSerialState) // Issue, damage, return or restock
 		}
 
 		// Protected routes (example)
 		protected := v1.Group("/protected")
-		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Use(middleware.AuthMiddleware(jwtManager), apiLimit, body)
 		{
 			// Add protected endpoints here
 		}
 	}
 }
+
+func rateLimitPolicy(p config.RateLimitPolicy) ratelimit.Policy {
+	return ratelimit.PerMinute(p.RequestsPerMinute, p.Burst)
+}
+
+// SetupMetrics serves the Prometheus metrics at /metrics
+func SetupMetrics(router *gin.Engine, cfg *config.Config) {
+	router.GET("/metrics", middleware.MetricsAuth(cfg.Metrics.Token), gin.WrapH(metrics.Handler()))
+}
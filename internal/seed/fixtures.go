@@ -0,0 +1,174 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+
+	"tiger-fasttrack-card/internal/cardnumber"
+	"tiger-fasttrack-card/internal/models"
+)
+
+// The fixtures are generated, not random: the same arguments always give the same data,
+// so tests can rely on it and seeding twice finds what the first run created.
+
+// Cards returns the sample card master data; every card has a numbering scheme, so card
+// numbers are allocated on registration
+func Cards() []models.CreateCardRequest {
+	return []models.CreateCardRequest{
+		{
+			CardName:      "Tiger Gold",
+			CardNameTH:    "ไทเกอร์ โกลด์",
+			CardImage:     CardImage(color.RGBA{0xb8, 0x86, 0x0b, 0xff}, color.RGBA{0xf5, 0xd0, 0x6f, 0xff}),
+			Category:      "airport",
+			Tier:          "Gold",
+			Price:         250000,
+			Benefits:      []string{"Fast track immigration", "Priority security lane"},
+			DescriptionEN: "Fast track through immigration and security at Suvarnabhumi Airport.",
+			DescriptionTH: "ผ่านช่องทางพิเศษตรวจคนเข้าเมืองและตรวจค้นที่ท่าอากาศยานสุวรรณภูมิ",
+			DisplayOrder:  10,
+			NumberPrefix:  "4101",
+			NumberLength:  16,
+			CheckDigit:    cardnumber.CheckDigitLuhn,
+		},
+		{
+			CardName:      "Tiger Platinum",
+			CardNameTH:    "ไทเกอร์ แพลทินัม",
+			CardImage:     CardImage(color.RGBA{0x5f, 0x67, 0x6f, 0xff}, color.RGBA{0xe5, 0xe4, 0xe2, 0xff}),
+			Category:      "airport",
+			Tier:          "Platinum",
+			Price:         590000,
+			Benefits:      []string{"Fast track immigration", "Priority security lane", "Lounge access", "Limousine transfer"},
+			DescriptionEN: "Fast track, lounge access and a limousine transfer for every trip.",
+			DescriptionTH: "ช่องทางพิเศษ ห้องรับรอง และรถลีมูซีนรับส่งทุกการเดินทาง",
+			DisplayOrder:  20,
+			NumberPrefix:  "4102",
+			NumberLength:  16,
+			CheckDigit:    cardnumber.CheckDigitLuhn,
+		},
+		{
+			CardName:      "Tiger Lounge",
+			CardNameTH:    "ไทเกอร์ เลานจ์",
+			CardImage:     CardImage(color.RGBA{0x0b, 0x3d, 0x2e, 0xff}, color.RGBA{0x3c, 0xb3, 0x71, 0xff}),
+			Category:      "airport",
+			Tier:          "Silver",
+			Price:         120000,
+			Benefits:      []string{"Lounge access"},
+			DescriptionEN: "Lounge access before every departure.",
+			DescriptionTH: "ใช้บริการห้องรับรองก่อนออกเดินทาง",
+			DisplayOrder:  30,
+			NumberPrefix:  "4103",
+			NumberLength:  16,
+			CheckDigit:    cardnumber.CheckDigitLuhn,
+		},
+		{
+			CardName:      "Tiger Lifestyle",
+			CardNameTH:    "ไทเกอร์ ไลฟ์สไตล์",
+			CardImage:     CardImage(color.RGBA{0x6a, 0x1b, 0x4d, 0xff}, color.RGBA{0xe9, 0x5c, 0x8f, 0xff}),
+			Category:      "lifestyle",
+			Tier:          "Classic",
+			Price:         90000,
+			Benefits:      []string{"Partner restaurant discounts", "Spa discounts"},
+			DescriptionEN: "Discounts at partner restaurants and spas in Bangkok.",
+			DescriptionTH: "ส่วนลดร้านอาหารและสปาพันธมิตรในกรุงเทพฯ",
+			DisplayOrder:  40,
+			NumberPrefix:  "4201",
+			NumberLength:  12,
+			CheckDigit:    cardnumber.CheckDigitNone,
+		},
+	}
+}
+
+// CardImage draws a card-shaped gradient from one color to another and returns it as a
+// PNG data URL
+func CardImage(from, to color.RGBA) string {
+	const width, height = 172, 108
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := float64(x+y) / float64(width+height-2)
+			img.SetRGBA(x, y, color.RGBA{
+				R: blend(from.R, to.R, t),
+				G: blend(from.G, to.G, t),
+				B: blend(from.B, to.B, t),
+				A: 0xff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err) // Encoding an in-memory image can't fail
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func blend(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
+
+// Owner is a generated card holder and the card registered to them
+type Owner struct {
+	NationalID  string
+	PhoneNumber string
+	FirstName   string
+	LastName    string
+	Email       string
+	CardName    string // One of Cards
+}
+
+var (
+	firstNames = []string{"สมชาย", "สมหญิง", "ประเสริฐ", "วิภาวรรณ", "ณัฐพล", "กมลชนก", "ธนากร", "พิมพ์ชนก", "อนุชา", "ศิริพร", "ปิยะ", "สุดารัตน์"}
+	lastNames  = []string{"ใจดี", "สุขสวัสดิ์", "ศรีสุข", "วงศ์ใหญ่", "ทองคำ", "แก้วมณี", "บุญมา", "รัตนพันธ์", "จันทร์เพ็ญ", "พงษ์ไพบูลย์"}
+)
+
+// Owners generates count card holders from randomSeed. Owner i doesn't depend on count,
+// so a larger count only adds owners
+func Owners(count int, randomSeed uint64) []Owner {
+	r := rand.New(rand.NewPCG(randomSeed, 0))
+	cards := Cards()
+	owners := make([]Owner, count)
+	for i := range owners {
+		owners[i] = Owner{
+			NationalID:  ThaiNationalID(r),
+			PhoneNumber: PhoneNumber(r),
+			FirstName:   firstNames[r.IntN(len(firstNames))],
+			LastName:    lastNames[r.IntN(len(lastNames))],
+			Email:       fmt.Sprintf("owner%04d@example.com", i+1),
+			CardName:    cards[r.IntN(len(cards))].CardName,
+		}
+	}
+	return owners
+}
+
+// ThaiNationalID generates a 13-digit Thai national ID with a valid check digit; the first
+// digit, the kind of registration, is 1 to 8 like real IDs
+func ThaiNationalID(r *rand.Rand) string {
+	digits := make([]byte, 13)
+	digits[0] = byte('1' + r.IntN(8))
+	for i := 1; i < 12; i++ {
+		digits[i] = byte('0' + r.IntN(10))
+	}
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(digits[i]-'0') * (13 - i)
+	}
+	digits[12] = byte('0' + (11-sum%11)%10)
+	return string(digits)
+}
+
+// PhoneNumber generates a 10-digit Thai mobile number (06, 08 or 09)
+func PhoneNumber(r *rand.Rand) string {
+	prefixes := []string{"06", "08", "09"}
+	return prefixes[r.IntN(len(prefixes))] + fmt.Sprintf("%08d", r.IntN(100000000))
+}
+
+// ownerDocument is the identity document an owner is registered with
+func ownerDocument(owner Owner) models.IdentityDocument {
+	return models.IdentityDocument{Type: models.DocumentNationalID, Country: models.DefaultIssuingCountry, Number: owner.NationalID}
+}
@@ -0,0 +1,223 @@
+// Package seed fills a development or test database with deterministic sample data: a user
+// of each role, card master data with images and generated card holders. Seeding is
+// idempotent: rows that already exist are left alone, so running it again is harmless.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+	"tiger-fasttrack-card/internal/service"
+)
+
+// DefaultPassword is the password of the seeded users; it meets the default password policy
+const DefaultPassword = "FastTrack-Dev-2025"
+
+// Users are the seeded accounts, one per role
+var Users = []models.CreateUserRequest{
+	{
+		RegisterRequest: models.RegisterRequest{Username: "superadmin", Email: "superadmin@example.com", FirstName: "Super", LastName: "Admin"},
+		Role:            models.RoleSuperAdmin,
+	},
+	{
+		RegisterRequest: models.RegisterRequest{Username: "admin", Email: "admin@example.com", FirstName: "HQ", LastName: "Admin"},
+		Role:            "admin",
+	},
+	{
+		RegisterRequest: models.RegisterRequest{Username: "staff", Email: "staff@example.com", FirstName: "HQ", LastName: "Staff"},
+		Role:            "user",
+	},
+}
+
+// RegistrarUsername is the seeded user who registers the generated card holders, and
+// AdminUsername the one who fills in their contact details
+const (
+	RegistrarUsername = "staff"
+	AdminUsername     = "admin"
+)
+
+// Options configures a seeding run
+type Options struct {
+	Password   string // Password of the seeded users, DefaultPassword when empty
+	Owners     int    // Number of card holders to generate, see Owners
+	RandomSeed uint64 // Seed of the generated card holders; the same seed gives the same people
+}
+
+// Counts tallies the rows of one kind a run created and those it found already present
+type Counts struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+// Report summarizes a seeding run
+type Report struct {
+	Users  Counts `json:"users"`
+	Cards  Counts `json:"cards"`
+	Owners Counts `json:"owners"`
+}
+
+// Seeder creates the sample data through the services, so it gets the same validation
+// as data entered through the API
+type Seeder struct {
+	repo *repository.Repository
+	svc  *service.Service
+}
+
+// New creates a Seeder
+func New(repo *repository.Repository, svc *service.Service) *Seeder {
+	return &Seeder{repo: repo, svc: svc}
+}
+
+// Run seeds the sample data
+func (s *Seeder) Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Password == "" {
+		opts.Password = DefaultPassword
+	}
+	if opts.Owners < 0 {
+		return nil, errors.New("number of owners can't be negative")
+	}
+
+	report := &Report{}
+	if err := s.seedUsers(ctx, opts, &report.Users); err != nil {
+		return report, err
+	}
+	if err := s.seedCards(ctx, &report.Cards); err != nil {
+		return report, err
+	}
+	if err := s.seedOwners(ctx, opts, &report.Owners); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func (s *Seeder) seedUsers(ctx context.Context, opts Options, counts *Counts) error {
+	repo := s.repo.WithContext(ctx)
+	for _, user := range Users {
+		_, err := repo.GetUserByUsername(user.Username)
+		if err == nil {
+			counts.Existing++
+			continue
+		}
+		if !notFound(err, "user_not_found") {
+			return fmt.Errorf("user %s: %w", user.Username, err)
+		}
+
+		req := user
+		req.Password = opts.Password
+		if _, err := s.svc.CreateUser(ctx, &req); err != nil {
+			return fmt.Errorf("user %s: %w", user.Username, err)
+		}
+		counts.Created++
+	}
+	return nil
+}
+
+func (s *Seeder) seedCards(ctx context.Context, counts *Counts) error {
+	repo := s.repo.WithContext(ctx)
+	var missing []models.CreateCardRequest
+	for _, card := range Cards() {
+		_, err := repo.GetCardByName(card.CardName)
+		if err == nil {
+			counts.Existing++
+			continue
+		}
+		if !notFound(err, "card_not_found") {
+			return fmt.Errorf("card %s: %w", card.CardName, err)
+		}
+		missing = append(missing, card)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	result, err := s.svc.ImportCards(ctx, missing)
+	if result != nil {
+		counts.Created += result.Created
+	}
+	return err
+}
+
+// seedOwners registers each generated card holder whose national ID isn't known yet, as
+// the registrar would at the counter, and names them as an admin would. Holders a failed
+// run registered but didn't name are named on the next run
+func (s *Seeder) seedOwners(ctx context.Context, opts Options, counts *Counts) error {
+	owners := Owners(opts.Owners, opts.RandomSeed)
+	if len(owners) == 0 {
+		return nil
+	}
+
+	repo := s.repo.WithContext(ctx)
+	registrar, err := repo.GetUserByUsername(RegistrarUsername)
+	if err != nil {
+		return fmt.Errorf("registrar %s: %w", RegistrarUsername, err)
+	}
+	admin, err := repo.GetUserByUsername(AdminUsername)
+	if err != nil {
+		return fmt.Errorf("admin %s: %w", AdminUsername, err)
+	}
+
+	cardIDs := make(map[string]uint)
+	for _, owner := range owners {
+		person, err := repo.AllTenants().GetPersonByDocument(ownerDocument(owner))
+		if err == nil {
+			if person.FirstName == "" {
+				if err := s.nameOwner(ctx, admin.ID, person.ID, owner); err != nil {
+					return err
+				}
+			}
+			counts.Existing++
+			continue
+		}
+		if !notFound(err, "person_not_found") {
+			return fmt.Errorf("owner %s: %w", owner.NationalID, err)
+		}
+
+		cardID, ok := cardIDs[owner.CardName]
+		if !ok {
+			card, err := repo.GetCardByName(owner.CardName)
+			if err != nil {
+				return fmt.Errorf("card %s: %w", owner.CardName, err)
+			}
+			cardID = card.ID
+			cardIDs[owner.CardName] = cardID
+		}
+
+		registration, err := s.svc.RegisterCardOwner(ctx, registrar.ID, &models.RegisterOwnerRequest{
+			CardID:      cardID,
+			IDCard:      owner.NationalID,
+			PhoneNumber: owner.PhoneNumber,
+		})
+		if err != nil {
+			return fmt.Errorf("owner %s: %w", owner.NationalID, err)
+		}
+		if err := s.nameOwner(ctx, admin.ID, registration.PersonID, owner); err != nil {
+			return err
+		}
+		counts.Created++
+	}
+	return nil
+}
+
+// nameOwner sets the generated name and email of a registered card holder
+func (s *Seeder) nameOwner(ctx context.Context, adminID, personID uint, owner Owner) error {
+	_, err := s.svc.UpdatePerson(ctx, adminID, personID, &models.UpdatePersonRequest{
+		FirstName: owner.FirstName,
+		LastName:  owner.LastName,
+		Email:     owner.Email,
+	})
+	if err != nil {
+		return fmt.Errorf("owner %s: %w", owner.NationalID, err)
+	}
+	return nil
+}
+
+// notFound reports whether err is the repository's not-found error with code; any other
+// error, e.g. a lost connection, fails the run rather than seeding a duplicate
+func notFound(err error, code string) bool {
+	var coded *i18n.Error
+	return errors.As(err, &coded) && coded.Code == code
+}
@@ -0,0 +1,169 @@
+package seed
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"tiger-fasttrack-card/internal/cardnumber"
+	"tiger-fasttrack-card/internal/config"
+	"tiger-fasttrack-card/internal/database"
+	"tiger-fasttrack-card/internal/lockout"
+	"tiger-fasttrack-card/internal/migrations"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+	"tiger-fasttrack-card/internal/service"
+	"tiger-fasttrack-card/internal/utils"
+)
+
+func TestOwnersAreDeterministic(t *testing.T) {
+	first := Owners(20, 42)
+	if !reflect.DeepEqual(first, Owners(20, 42)) {
+		t.Fatal("Owners() differs between calls with the same seed")
+	}
+	if !reflect.DeepEqual(first[:5], Owners(5, 42)) {
+		t.Error("a larger count changed the first owners")
+	}
+	if reflect.DeepEqual(first, Owners(20, 43)) {
+		t.Error("another seed gave the same owners")
+	}
+	if len(Owners(0, 42)) != 0 {
+		t.Error("Owners(0) isn't empty")
+	}
+}
+
+func TestOwnersAreValid(t *testing.T) {
+	cards := make(map[string]bool)
+	for _, card := range Cards() {
+		cards[card.CardName] = true
+	}
+
+	seen := make(map[string]bool)
+	for i, owner := range Owners(200, 1) {
+		doc, err := ownerDocument(owner).Normalize()
+		if err != nil {
+			t.Errorf("owner %d: national ID %s: %v", i, owner.NationalID, err)
+		}
+		if doc.Number != owner.NationalID {
+			t.Errorf("owner %d: national ID %s isn't normalized", i, owner.NationalID)
+		}
+		if seen[owner.NationalID] {
+			t.Errorf("owner %d: national ID %s generated twice", i, owner.NationalID)
+		}
+		seen[owner.NationalID] = true
+
+		if len(owner.PhoneNumber) != 10 || !slices.Contains([]string{"06", "08", "09"}, owner.PhoneNumber[:2]) {
+			t.Errorf("owner %d: phone number %s isn't a Thai mobile number", i, owner.PhoneNumber)
+		}
+		if owner.FirstName == "" || owner.LastName == "" || owner.Email == "" {
+			t.Errorf("owner %d: %+v has no name or email", i, owner)
+		}
+		if !cards[owner.CardName] {
+			t.Errorf("owner %d: unknown card %q", i, owner.CardName)
+		}
+	}
+}
+
+func TestThaiNationalIDChecksum(t *testing.T) {
+	r := rand.New(rand.NewPCG(7, 0))
+	for i := 0; i < 1000; i++ {
+		id := ThaiNationalID(r)
+		if id[0] < '1' || id[0] > '8' {
+			t.Fatalf("ThaiNationalID() = %s, first digit must be 1 to 8", id)
+		}
+		sum := 0
+		for j := 0; j < 12; j++ {
+			sum += int(id[j]-'0') * (13 - j)
+		}
+		if want := byte('0' + (11-sum%11)%10); id[12] != want {
+			t.Fatalf("ThaiNationalID() = %s, check digit should be %c", id, want)
+		}
+	}
+}
+
+func TestCardsHaveValidSchemes(t *testing.T) {
+	names := make(map[string]bool)
+	for _, card := range Cards() {
+		if names[card.CardName] {
+			t.Errorf("%s is listed twice", card.CardName)
+		}
+		names[card.CardName] = true
+
+		scheme := cardnumber.Scheme{Prefix: card.NumberPrefix, Length: card.NumberLength, CheckDigit: card.CheckDigit}
+		if !scheme.Enabled() {
+			t.Errorf("%s has no numbering scheme", card.CardName)
+		}
+		if err := scheme.Validate(); err != nil {
+			t.Errorf("%s: %v", card.CardName, err)
+		}
+		if !strings.HasPrefix(card.CardImage, "data:image/png;base64,") {
+			t.Errorf("%s has no embedded image", card.CardName)
+		}
+	}
+}
+
+// TestRunTwice seeds the database named by TEST_DATABASE_URL twice; the second run must
+// find everything the first created. It is skipped without a database.
+func TestRunTwice(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	cfg := config.Default("test")
+	cfg.DatabaseURL = url
+	db, err := database.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := migrations.RunMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+
+	passwords := utils.DefaultPasswordPolicy()
+	passwords.HashCost = 4
+	repo := repository.New(db)
+	svc := service.New(repo, nil, lockout.NewGuard(lockout.NewMemoryStore(), lockout.Policy{}, lockout.Policy{}), passwords,
+		service.PasswordResetOptions{}, service.TwoFactorOptions{}, cfg.OAuth.ClientTokenTTL, service.OIDCOptions{}, service.RegistrationOptions{})
+	seeder := New(repo, svc)
+	ctx := context.Background()
+	opts := Options{Owners: 5, RandomSeed: 2024}
+
+	if _, err := seeder.Run(ctx, opts); err != nil {
+		t.Fatalf("first Run() = %v", err)
+	}
+
+	// A run that failed after registering a holder leaves them without a name
+	owner := Owners(opts.Owners, opts.RandomSeed)[0]
+	err = db.GetDB().Model(&models.Person{}).Where("document_number = ?", owner.NationalID).
+		Updates(map[string]any{"first_name": "", "last_name": "", "email": ""}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := seeder.Run(ctx, opts)
+	if err != nil {
+		t.Fatalf("second Run() = %v", err)
+	}
+	want := Report{
+		Users:  Counts{Existing: len(Users)},
+		Cards:  Counts{Existing: len(Cards())},
+		Owners: Counts{Existing: opts.Owners},
+	}
+	if *report != want {
+		t.Errorf("second Run() = %+v, want %+v", *report, want)
+	}
+
+	person, err := repo.AllTenants().GetPersonByDocument(ownerDocument(owner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.FirstName != owner.FirstName || person.LastName != owner.LastName || person.Email != owner.Email {
+		t.Errorf("nameless holder wasn't named: %+v", person)
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+)
+
+// AdminService runs the operator commands of the binary; the caller has database access,
+// so there's no signed-in user and no permission check
+type AdminService struct {
+	repo        *repository.Repository
+	authService *AuthService
+}
+
+// NewAdminService creates a new AdminService instance
+func NewAdminService(repo *repository.Repository, authService *AuthService) *AdminService {
+	return &AdminService{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *AdminService) withContext(ctx context.Context) *AdminService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// CreateUser creates an active account with any role, e.g. the first super admin
+// Users other than super admins join the given branch or organization, by default the
+// default organization
+func (s *AdminService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	switch role {
+	case "":
+		role = "user"
+	case "user", "admin", models.RoleSuperAdmin:
+	default:
+		return nil, i18n.NewError("invalid_user_role")
+	}
+
+	user, err := s.authService.newUser(&req.RegisterRequest)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	if role == models.RoleSuperAdmin {
+		if req.OrganizationCode != "" || req.BranchCode != "" {
+			return nil, i18n.NewError("super_admin_no_organization")
+		}
+	} else {
+		organizationID, branchID, err := s.tenantByCode(req.OrganizationCode, req.BranchCode)
+		if err != nil {
+			return nil, err
+		}
+		user.OrganizationID = &organizationID
+		user.BranchID = branchID
+	}
+
+	if err := s.repo.CreateUser(user); err != nil {
+		return nil, i18n.NewError("failed_to_create_user")
+	}
+	return user, nil
+}
+
+// tenantByCode resolves an organization and branch code like placement resolves IDs
+func (s *AdminService) tenantByCode(organizationCode, branchCode string) (uint, *uint, error) {
+	var org *models.Organization
+	if organizationCode != "" {
+		var err error
+		if org, err = s.repo.GetOrganizationByCode(organizationCode); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if branchCode != "" {
+		branch, err := s.repo.GetBranchByCode(branchCode)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !branch.IsActive {
+			return 0, nil, i18n.NewError("branch_not_active")
+		}
+		if org != nil && org.ID != branch.OrganizationID {
+			return 0, nil, i18n.NewError("branch_other_organization")
+		}
+		if err := activeOrganization(s.repo, branch.OrganizationID); err != nil {
+			return 0, nil, err
+		}
+		return branch.OrganizationID, &branch.ID, nil
+	}
+
+	if org == nil {
+		var err error
+		if org, err = s.repo.GetOrganizationByCode(models.DefaultOrganizationCode); err != nil {
+			return 0, nil, err
+		}
+	}
+	if !org.IsActive {
+		return 0, nil, i18n.NewError("organization_not_active")
+	}
+	return org.ID, nil, nil
+}
+
+// ResetPassword sets a user's password without the current one, signs out their
+// sessions and clears their login lockout
+func (s *AdminService) ResetPassword(username, password string) (*models.User, error) {
+	user, err := s.repo.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if !s.authService.allowsLocalLogin(user) {
+		return nil, ErrLocalLoginDisabled
+	}
+
+	if err := s.authService.passwords.Validate(password, user.Username); err != nil {
+		return nil, err
+	}
+	if err := s.authService.checkPasswordReuse(user, password); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.authService.passwords.Hash(password)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_hash_new_password")
+	}
+
+	previousHash := user.Password
+	user.Password = hashedPassword
+	user.TokenVersion++
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, i18n.NewError("failed_to_reset_password")
+	}
+
+	s.authService.recordPasswordHistory(user.ID, previousHash)
+
+	if err := s.repo.InvalidatePasswordResetTokens(user.ID); err != nil {
+		slog.Error("failed to invalidate reset tokens", "user_id", user.ID, "error", err)
+	}
+	if err := s.authService.loginGuard.Unlock(user.Username); err != nil {
+		slog.Error("failed to clear login lockout", "user_id", user.ID, "error", err)
+	}
+
+	return user, nil
+}
+
+// ImportCards adds cards to the master data, or updates the card with the same name, so
+// importing a file again is harmless. Every card is validated before any is saved, and
+// they're saved together or not at all
+func (s *AdminService) ImportCards(reqs []models.CreateCardRequest) (*models.CardImportResult, error) {
+	imports := make([]models.CardImport, 0, len(reqs))
+	seen := make(map[string]bool)
+	for i := range reqs {
+		req := &reqs[i]
+		req.CardName = strings.TrimSpace(req.CardName)
+		if req.CardName == "" {
+			return nil, fmt.Errorf("card %d: card name is required", i+1)
+		}
+		if seen[req.CardName] {
+			return nil, fmt.Errorf("%s: card is listed twice", req.CardName)
+		}
+		seen[req.CardName] = true
+		if req.CardImage == "" {
+			return nil, fmt.Errorf("%s: card image is required", req.CardName)
+		}
+
+		card, err := newCard(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", req.CardName, err)
+		}
+		imports = append(imports, models.CardImport{Card: card, SetsActive: req.IsActive != nil})
+	}
+
+	return s.repo.ImportCards(imports)
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/lockout"
+	"tiger-fasttrack-card/internal/metrics"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+	"tiger-fasttrack-card/internal/utils"
+	"time"
+)
+
+// OAuth2 error codes (RFC 6749 section 5.2)
+var (
+	ErrInvalidClient        = i18n.NewError("invalid_client")
+	ErrInvalidScope         = i18n.NewError("invalid_scope")
+	ErrUnsupportedGrantType = i18n.NewError("unsupported_grant_type")
+)
+
+// APIClientService manages partner API clients and the client-credentials grant
+type APIClientService struct {
+	repo        *repository.Repository
+	authService *AuthService
+	tokenTTL    time.Duration
+}
+
+// NewAPIClientService creates a new APIClientService instance
+func NewAPIClientService(repo *repository.Repository, authService *AuthService, tokenTTL time.Duration) *APIClientService {
+	return &APIClientService{
+		repo:        repo,
+		authService: authService,
+		tokenTTL:    tokenTTL,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *APIClientService) withContext(ctx context.Context) *APIClientService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// CreateClient registers a client and returns its credentials (admin only)
+// The client works in the admin's tenant unless another organization or branch is given
+func (s *APIClientService) CreateClient(adminID uint, req *models.CreateAPIClientRequest) (*models.APIClientCredentials, error) {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return nil, err
+	}
+	organizationID, branchID, err := placement(s.repo, admin, req.OrganizationID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, err := randomHex(8)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_generate_client_id")
+	}
+	clientID = "tfc_" + clientID
+
+	secret, secretHash, err := s.newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// The service user owns the client's registrations; its password is random and
+	// never disclosed, and Login refuses the api_client role anyway
+	unusablePassword, _, err := s.newSecret()
+	if err != nil {
+		return nil, err
+	}
+	unusableHash, err := s.authService.passwords.Hash(unusablePassword)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_create_api_client")
+	}
+	serviceUser := &models.User{
+		Username:  "client:" + clientID,
+		Password:  unusableHash,
+		FirstName: req.Name,
+		LastName:  "(API client)",
+		IsActive:  true,
+		Role:      models.RoleAPIClient,
+
+		OrganizationID: &organizationID,
+		BranchID:       branchID,
+	}
+
+	client := &models.APIClient{
+		ClientID:    clientID,
+		Name:        req.Name,
+		SecretHash:  secretHash,
+		Scopes:      strings.Join(scopes, " "),
+		IsActive:    true,
+		CreatedByID: adminID,
+	}
+	if err := s.repo.CreateAPIClient(client, serviceUser); err != nil {
+		return nil, i18n.NewError("failed_to_create_api_client")
+	}
+
+	return &models.APIClientCredentials{Client: *client, ClientSecret: secret}, nil
+}
+
+// ListClients returns the API clients in the admin's tenant (admin only)
+func (s *APIClientService) ListClients(adminID uint) ([]models.APIClient, error) {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForTenant(admin.Tenant()).GetAllAPIClients()
+}
+
+// RotateSecret issues a new secret; the old one stops working immediately (admin only)
+func (s *APIClientService) RotateSecret(adminID uint, id uint) (*models.APIClientCredentials, error) {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.repo.ForTenant(admin.Tenant()).GetAPIClientByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive {
+		return nil, i18n.NewError("api_client_revoked")
+	}
+
+	secret, secretHash, err := s.newSecret()
+	if err != nil {
+		return nil, err
+	}
+	client.SecretHash = secretHash
+	if err := s.repo.UpdateAPIClient(client); err != nil {
+		return nil, i18n.NewError("failed_to_rotate_secret")
+	}
+
+	return &models.APIClientCredentials{Client: *client, ClientSecret: secret}, nil
+}
+
+// RevokeClient deactivates a client and its service user (admin only)
+func (s *APIClientService) RevokeClient(adminID uint, id uint) error {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.repo.ForTenant(admin.Tenant()).GetAPIClientByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RevokeAPIClient(client); err != nil {
+		return i18n.NewError("failed_to_revoke_api_client")
+	}
+	return nil
+}
+
+// IssueToken implements the client-credentials grant
+// Failed secrets count towards the same lockout used for user logins
+func (s *APIClientService) IssueToken(req *models.ClientTokenRequest, clientIP string) (*models.ClientTokenResponse, error) {
+	if req.GrantType != "client_credentials" {
+		return nil, ErrUnsupportedGrantType
+	}
+	if req.ClientID == "" || req.ClientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	guardKey := "client:" + req.ClientID
+	if err := s.authService.loginGuard.Check(guardKey, clientIP); err != nil {
+		var locked *lockout.LockedError
+		if errors.As(err, &locked) {
+			metrics.LoginFailures.WithLabelValues(metrics.LoginFailureLockedOut).Inc()
+			return nil, err
+		}
+		return nil, i18n.NewError("failed_to_verify_login")
+	}
+
+	client, err := s.repo.GetAPIClientByClientID(req.ClientID)
+	if err != nil || !client.IsActive || !utils.CheckPassword(req.ClientSecret, client.SecretHash) {
+		s.authService.recordLoginFailure(guardKey, clientIP, metrics.LoginFailureClientSecret)
+		return nil, ErrInvalidClient
+	}
+
+	// Default to every granted scope; a requested scope must be a subset
+	granted := strings.Fields(client.Scopes)
+	scopes := granted
+	if req.Scope != "" {
+		scopes = strings.Fields(req.Scope)
+		for _, scope := range scopes {
+			if !containsString(granted, scope) {
+				return nil, ErrInvalidScope
+			}
+		}
+	}
+	scope := strings.Join(scopes, " ")
+
+	serviceUser, err := s.authService.ValidateUserAccess(client.UserID)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+
+	token, err := s.authService.jwtManager.GenerateClientToken(serviceUser.ID, serviceUser.Username, serviceUser.Role, client.ClientID, scope, s.tokenTTL)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_generate_token")
+	}
+
+	if err := s.authService.loginGuard.RecordSuccess(guardKey); err != nil {
+		slog.Error("failed to reset login attempts", "key", guardKey, "error", err)
+	}
+	if err := s.repo.TouchAPIClient(client.ID); err != nil {
+		slog.Error("failed to record API client use", "client_id", client.ClientID, "error", err)
+	}
+
+	return &models.ClientTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.tokenTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// newSecret returns a random client secret and its bcrypt hash
+func (s *APIClientService) newSecret() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", i18n.NewError("failed_to_generate_secret_key")
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	hash, err := s.authService.passwords.Hash(secret)
+	if err != nil {
+		return "", "", i18n.NewError("failed_to_hash_client_secret")
+	}
+	return secret, hash, nil
+}
+
+// normalizeScopes validates and de-duplicates requested scopes
+func normalizeScopes(requested []string) ([]string, error) {
+	var scopes []string
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !containsString(models.AllowedScopes, scope) {
+			return nil, i18n.NewError("unknown_scope", scope)
+		}
+		if !containsString(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
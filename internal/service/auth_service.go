@@ -1,85 +1,187 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/lockout"
+	"tiger-fasttrack-card/internal/metrics"
 	"tiger-fasttrack-card/internal/models"
 	"tiger-fasttrack-card/internal/repository"
 	"tiger-fasttrack-card/internal/utils"
 )
 
+// ErrLocalLoginDisabled is returned when an account must sign in through single sign-on
+var ErrLocalLoginDisabled = i18n.NewError("local_login_disabled")
+
+// ErrInsufficientPermissions is returned when a user lacks the role an operation needs
+var ErrInsufficientPermissions = i18n.NewError("insufficient_permissions")
+
 // AuthService handles authentication and user management operations
 type AuthService struct {
 	repo       *repository.Repository
 	jwtManager *utils.JWTManager
+	loginGuard *lockout.Guard
+	passwords  *utils.PasswordPolicy
+	twoFactor  TwoFactorOptions
+
+	// Roles that must sign in through single sign-on instead of a password
+	localLoginDisabledRoles []string
+}
+
+// TwoFactorOptions configures TOTP two-factor authentication
+type TwoFactorOptions struct {
+	Issuer        string   // shown in authenticator apps
+	RequiredRoles []string // roles that must complete TOTP to log in
 }
 
 // NewAuthService creates a new AuthService instance
-func NewAuthService(repo *repository.Repository, jwtManager *utils.JWTManager) *AuthService {
+func NewAuthService(repo *repository.Repository, jwtManager *utils.JWTManager, loginGuard *lockout.Guard, passwords *utils.PasswordPolicy, twoFactor TwoFactorOptions, localLoginDisabledRoles []string) *AuthService {
 	return &AuthService{
 		repo:       repo,
 		jwtManager: jwtManager,
+		loginGuard: loginGuard,
+		passwords:  passwords,
+		twoFactor:  twoFactor,
+
+		localLoginDisabledRoles: localLoginDisabledRoles,
 	}
 }
 
-// Register creates a new user account
-func (s *AuthService) Register(req *models.RegisterRequest) (*models.User, error) {
+// withContext returns a copy whose queries run with ctx
+func (s *AuthService) withContext(ctx context.Context) *AuthService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	return &clone
+}
+
+// newUser validates a registration and returns the unsaved user with role "user";
+// registration mode, invitations and verification are handled by RegistrationService
+func (s *AuthService) newUser(req *models.RegisterRequest) (*models.User, error) {
 	// Check if username is taken
 	existingUser, _ := s.repo.GetUserByUsername(req.Username)
 	if existingUser != nil {
-		return nil, errors.New("username is already taken")
+		return nil, i18n.NewError("username_taken")
+	}
+
+	// Check if email is taken
+	if req.Email != "" {
+		existingUser, _ = s.repo.GetUserByEmail(req.Email)
+		if existingUser != nil {
+			return nil, i18n.NewError("email_registered")
+		}
+	}
+
+	// Enforce password policy
+	if err := s.passwords.Validate(req.Password, req.Username); err != nil {
+		return nil, err
 	}
 
 	// Hash password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
-		return nil, errors.New("failed to hash password")
+		return nil, i18n.NewError("failed_to_hash_password")
 	}
 
-	// Create user
-	user := &models.User{
+	return &models.User{
 		Username:  req.Username,
 		Password:  hashedPassword,
+		Email:     req.Email,
+		Phone:     req.Phone,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		IsActive:  true,
 		Role:      "user",
-	}
-
-	err = s.repo.CreateUser(user)
-	if err != nil {
-		return nil, errors.New("failed to create user")
-	}
-
-	return user, nil
+	}, nil
 }
 
 // Login authenticates a user and returns tokens
-func (s *AuthService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+// Failed attempts are counted per username and per client IP; a *lockout.LockedError
+// is returned while either is locked out
+func (s *AuthService) Login(req *models.LoginRequest, clientIP string) (*models.LoginResponse, error) {
+	// Reject early if the username or IP is locked out
+	if err := s.loginGuard.Check(req.Username, clientIP); err != nil {
+		var locked *lockout.LockedError
+		if errors.As(err, &locked) {
+			metrics.LoginFailures.WithLabelValues(metrics.LoginFailureLockedOut).Inc()
+			return nil, err
+		}
+		return nil, i18n.NewError("failed_to_verify_login")
+	}
+
 	// Get user by username
 	user, err := s.repo.GetUserByUsername(req.Username)
 	if err != nil {
-		return nil, errors.New("invalid username or password")
+		// Count unknown usernames too so they can't be probed for free
+		s.recordLoginFailure(req.Username, clientIP, metrics.LoginFailurePassword)
+		return nil, i18n.NewError("invalid_credentials")
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+		if user.PendingVerification != "" {
+			return nil, i18n.NewError("account_pending_verification", user.PendingVerification)
+		}
+		return nil, i18n.NewError("account_deactivated")
+	}
+
+	// API client service users authenticate with the client-credentials grant only
+	if user.Role == models.RoleAPIClient {
+		s.recordLoginFailure(req.Username, clientIP, metrics.LoginFailurePassword)
+		return nil, i18n.NewError("invalid_credentials")
 	}
 
 	// Verify password
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid username or password")
+		s.recordLoginFailure(req.Username, clientIP, metrics.LoginFailurePassword)
+		return nil, i18n.NewError("invalid_credentials")
+	}
+
+	// Staff roles and SSO accounts must use the identity provider; checked after the
+	// password so the response doesn't reveal the account's role
+	if !s.allowsLocalLogin(user) {
+		return nil, ErrLocalLoginDisabled
+	}
+
+	// Transparently upgrade hashes created with a lower bcrypt cost
+	if s.passwords.NeedsRehash(user.Password) {
+		s.rehashPassword(user, req.Password)
+	}
+
+	// Second step required: hand out a challenge token instead of real tokens
+	if user.TOTPEnabled || s.requiresTwoFactor(user) {
+		challengeToken, err := s.jwtManager.GenerateChallengeToken(user.ID, user.Username, user.Role)
+		if err != nil {
+			return nil, i18n.NewError("failed_to_generate_token")
+		}
+		return &models.LoginResponse{
+			User:                   *user,
+			TwoFactorRequired:      user.TOTPEnabled,
+			TwoFactorSetupRequired: !user.TOTPEnabled,
+			ChallengeToken:         challengeToken,
+		}, nil
+	}
+
+	return s.issueTokens(user)
+}
+
+// issueTokens generates the access and refresh tokens for a fully authenticated user
+// The failed-attempt counter is only reset here so a known password can't be used
+// to reset it between second-factor guesses
+func (s *AuthService) issueTokens(user *models.User) (*models.LoginResponse, error) {
+	if err := s.loginGuard.RecordSuccess(user.Username); err != nil {
+		slog.Error("failed to reset login attempts", "username", user.Username, "error", err)
 	}
 
-	// Generate tokens
 	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, i18n.NewError("failed_to_generate_token")
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID, user.Username, user.Role)
+	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID, user.Username, user.Role, user.TokenVersion)
 	if err != nil {
-		return nil, errors.New("failed to generate refresh token")
+		return nil, i18n.NewError("failed_to_generate_refresh")
 	}
 
 	return &models.LoginResponse{
@@ -89,22 +191,106 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 	}, nil
 }
 
+// allowsLocalLogin reports whether the user may authenticate with a local password
+func (s *AuthService) allowsLocalLogin(user *models.User) bool {
+	if user.SSO() {
+		return false
+	}
+	for _, role := range s.localLoginDisabledRoles {
+		if role == user.Role {
+			return false
+		}
+	}
+	return true
+}
+
+// requiresTwoFactor reports whether the user's role must use two-factor login
+func (s *AuthService) requiresTwoFactor(user *models.User) bool {
+	for _, role := range s.twoFactor.RequiredRoles {
+		if role == user.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// recordLoginFailure counts a failed login; store errors are logged so they
+// don't mask the "invalid username or password" response
+func (s *AuthService) recordLoginFailure(username, clientIP string, reason string) {
+	metrics.LoginFailures.WithLabelValues(reason).Inc()
+	if err := s.loginGuard.RecordFailure(username, clientIP); err != nil {
+		slog.Error("failed to record login failure", "username", username, "error", err)
+	}
+}
+
+// rehashPassword stores a new hash at the configured cost; failures are only logged
+// since the user has already authenticated
+func (s *AuthService) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := s.passwords.Hash(password)
+	if err != nil {
+		slog.Error("failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	user.Password = hashedPassword
+	if err := s.repo.UpdateUser(user); err != nil {
+		slog.Error("failed to store rehashed password", "user_id", user.ID, "error", err)
+	}
+}
+
+// UnlockAccount clears the login lockout for a user (admin only)
+func (s *AuthService) UnlockAccount(adminID uint, targetUserID uint) error {
+	admin, err := s.requireAdmin(adminID)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(targetUserID)
+	if err != nil {
+		return err
+	}
+	if !admin.Tenant().Contains(tenantOf(user)) {
+		return i18n.NewError("user_not_found")
+	}
+
+	if err := s.loginGuard.Unlock(user.Username); err != nil {
+		return i18n.NewError("failed_to_unlock_account")
+	}
+	return nil
+}
+
 // RefreshToken generates a new access token from a refresh token
 func (s *AuthService) RefreshToken(refreshToken string) (string, error) {
 	claims, err := s.jwtManager.ValidateToken(refreshToken)
 	if err != nil {
-		return "", errors.New("invalid refresh token")
+		return "", i18n.NewError("invalid_refresh_token")
+	}
+	if claims.TokenType != "" && claims.TokenType != utils.TokenTypeRefresh {
+		return "", i18n.NewError("invalid_refresh_token")
+	}
+
+	// Refresh tokens issued before a password reset are revoked
+	user, err := s.ValidateUserAccess(claims.UserID)
+	if err != nil {
+		return "", i18n.NewError("invalid_refresh_token")
+	}
+	if claims.TokenVersion != user.TokenVersion {
+		return "", i18n.NewError("refresh_token_revoked")
 	}
 
 	// Generate new access token
-	newToken, err := s.jwtManager.GenerateToken(claims.UserID, claims.Username, claims.Role)
+	newToken, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
-		return "", errors.New("failed to generate new token")
+		return "", i18n.NewError("failed_to_generate_new_token")
 	}
 
 	return newToken, nil
 }
 
+// JWKS returns the public keys partners use to verify our tokens
+func (s *AuthService) JWKS() utils.JWKSet {
+	return s.jwtManager.JWKS()
+}
+
 // GetUserProfile retrieves user profile information
 func (s *AuthService) GetUserProfile(userID uint) (*models.User, error) {
 	return s.repo.GetUserByID(userID)
@@ -121,11 +307,20 @@ func (s *AuthService) UpdateUserProfile(userID uint, req *models.UpdateProfileRe
 	if req.Username != "" && req.Username != user.Username {
 		existingUser, _ := s.repo.GetUserByUsername(req.Username)
 		if existingUser != nil && existingUser.ID != userID {
-			return nil, errors.New("username is already taken")
+			return nil, i18n.NewError("username_taken")
 		}
 		user.Username = req.Username
 	}
 
+	// Check if email is taken by another user
+	if req.Email != "" && req.Email != user.Email {
+		existingUser, _ := s.repo.GetUserByEmail(req.Email)
+		if existingUser != nil && existingUser.ID != userID {
+			return nil, i18n.NewError("email_registered")
+		}
+		user.Email = req.Email
+	}
+
 	if req.FirstName != "" {
 		user.FirstName = req.FirstName
 	}
@@ -135,7 +330,7 @@ func (s *AuthService) UpdateUserProfile(userID uint, req *models.UpdateProfileRe
 
 	err = s.repo.UpdateUser(user)
 	if err != nil {
-		return nil, errors.New("failed to update profile")
+		return nil, i18n.NewError("failed_to_update_profile")
 	}
 
 	return user, nil
@@ -148,34 +343,112 @@ func (s *AuthService) ChangePassword(userID uint, req *models.ChangePasswordRequ
 		return err
 	}
 
+	if !s.allowsLocalLogin(user) {
+		return ErrLocalLoginDisabled
+	}
+
 	// Verify current password
 	if !utils.CheckPassword(req.CurrentPassword, user.Password) {
-		return errors.New("current password is incorrect")
+		return i18n.NewError("current_password_incorrect")
+	}
+
+	// Enforce password policy
+	if err := s.passwords.Validate(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+
+	// Block reuse of the current and recent passwords
+	if err := s.checkPasswordReuse(user, req.NewPassword); err != nil {
+		return err
 	}
 
 	// Hash new password
-	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	hashedPassword, err := s.passwords.Hash(req.NewPassword)
 	if err != nil {
-		return errors.New("failed to hash new password")
+		return i18n.NewError("failed_to_hash_new_password")
 	}
 
+	previousHash := user.Password
 	user.Password = hashedPassword
 	err = s.repo.UpdateUser(user)
 	if err != nil {
-		return errors.New("failed to update password")
+		return i18n.NewError("failed_to_update_password")
 	}
 
+	s.recordPasswordHistory(user.ID, previousHash)
+
 	return nil
 }
 
+// checkPasswordReuse rejects the current password and the last HistorySize passwords
+func (s *AuthService) checkPasswordReuse(user *models.User, newPassword string) error {
+	if utils.CheckPassword(newPassword, user.Password) {
+		return i18n.NewError("password_unchanged")
+	}
+	if s.passwords.HistorySize <= 0 {
+		return nil
+	}
+
+	history, err := s.repo.GetRecentPasswordHistory(user.ID, s.passwords.HistorySize)
+	if err != nil {
+		return i18n.NewError("failed_to_check_history")
+	}
+	for _, entry := range history {
+		if utils.CheckPassword(newPassword, entry.PasswordHash) {
+			return i18n.NewError("password_reused", s.passwords.HistorySize)
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory remembers a replaced password hash; failures are only logged
+// since the password change itself has already succeeded
+func (s *AuthService) recordPasswordHistory(userID uint, passwordHash string) {
+	if s.passwords.HistorySize <= 0 {
+		return
+	}
+	if err := s.repo.CreatePasswordHistory(&models.PasswordHistory{UserID: userID, PasswordHash: passwordHash}); err != nil {
+		slog.Error("failed to record password history", "user_id", userID, "error", err)
+		return
+	}
+	if err := s.repo.PrunePasswordHistory(userID, s.passwords.HistorySize); err != nil {
+		slog.Error("failed to prune password history", "user_id", userID, "error", err)
+	}
+}
+
 // ValidateUserAccess checks if user exists and is active
 func (s *AuthService) ValidateUserAccess(userID uint) (*models.User, error) {
 	user, err := s.repo.GetUserByID(userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, i18n.NewError("user_not_found")
 	}
 	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+		return nil, i18n.NewError("account_deactivated")
+	}
+	return user, nil
+}
+
+// requireAdmin returns the user when they are active and an admin or super admin; the
+// services use it to guard admin-only operations
+func (s *AuthService) requireAdmin(userID uint) (*models.User, error) {
+	user, err := s.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsAdmin() {
+		return nil, ErrInsufficientPermissions
+	}
+	return user, nil
+}
+
+// requireSuperAdmin returns the user when they are an active super admin
+func (s *AuthService) requireSuperAdmin(userID uint) (*models.User, error) {
+	user, err := s.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsSuperAdmin() {
+		return nil, ErrInsufficientPermissions
 	}
 	return user, nil
 }
@@ -1,8 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"strings"
+	"tiger-fasttrack-card/internal/cardnumber"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/metrics"
 	"tiger-fasttrack-card/internal/models"
 	"tiger-fasttrack-card/internal/repository"
 )
@@ -21,13 +26,39 @@ func NewCardOwnerService(repo *repository.Repository, authService *AuthService)
 	}
 }
 
+// withContext returns a copy whose queries run with ctx
+func (s *CardOwnerService) withContext(ctx context.Context) *CardOwnerService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
 // validateDuplicateCardRegistration checks if a card registration already exists
 // excludeID can be provided to exclude a specific card owner ID from the duplicate check (useful for updates)
-func (s *CardOwnerService) validateDuplicateCardRegistration(cardID uint, cardNumber string, excludeID ...uint) error {
+// The number is checked against the card's numbering scheme and returned normalized; it is
+// returned empty for a new registration that should get an allocated number
+func (s *CardOwnerService) validateDuplicateCardRegistration(cardID uint, cardNumber string, excludeID ...uint) (string, error) {
 	// Validate that the card ID exists in master data
-	_, err := s.repo.GetCardByID(cardID)
+	card, err := s.repo.GetCardByID(cardID)
 	if err != nil {
-		return errors.New("card ID not found in master data")
+		return "", i18n.NewError("card_id_not_found")
+	}
+
+	// New registrations need an active card; existing ones may keep a deactivated card
+	if !card.IsActive && len(excludeID) == 0 {
+		return "", i18n.NewError("card_not_available")
+	}
+
+	scheme := card.NumberScheme()
+	if scheme.Enabled() {
+		if cardNumber == "" && len(excludeID) == 0 {
+			return "", nil
+		}
+		cardNumber = cardnumber.Normalize(cardNumber)
+	}
+	if err := scheme.Check(cardNumber); err != nil {
+		return "", err
 	}
 
 	// Check if this card number for this card ID is already taken
@@ -35,52 +66,140 @@ func (s *CardOwnerService) validateDuplicateCardRegistration(cardID uint, cardNu
 	if existingByCardNumberAndID != nil {
 		// If excludeID is provided, check if the existing record is the one being excluded
 		if len(excludeID) > 0 && existingByCardNumberAndID.ID == excludeID[0] {
-			return nil // This is an update of the same record, allow it
+			return cardNumber, nil // This is an update of the same record, allow it
 		}
-		return errors.New("card number " + cardNumber + " is already registered for this card")
+		return "", i18n.NewError("card_number_registered", cardNumber)
 	}
 
-	return nil
+	return cardNumber, nil
+}
+
+// countDuplicateRejection counts a registration or update rejected because its card number
+// is already registered; the validation endpoints only ask, so they aren't counted
+func countDuplicateRejection(err error) {
+	var coded *i18n.Error
+	if errors.As(err, &coded) && coded.Code == "card_number_registered" {
+		metrics.DuplicateRejections.Inc()
+	}
+}
+
+// maxAllocationAttempts bounds how many registered numbers the allocator skips in one call
+const maxAllocationAttempts = 20
+
+// allocateCardNumber issues the next free number of the card's scheme; sequences are
+// taken atomically, and numbers that were already registered by hand are skipped
+func (s *CardOwnerService) allocateCardNumber(cardID uint) (string, error) {
+	for attempt := 0; attempt < maxAllocationAttempts; attempt++ {
+		card, sequence, err := s.repo.AllocateCardSequences(cardID, 1)
+		if err != nil {
+			return "", i18n.NewError("failed_to_allocate_card_number")
+		}
+		number, err := card.NumberScheme().Format(sequence)
+		if err != nil {
+			return "", err
+		}
+		if existing, _ := s.repo.GetCardOwnerByCardNumberAndCardID(number, cardID); existing == nil {
+			return number, nil
+		}
+	}
+	return "", i18n.NewError("failed_to_allocate_card_number")
+}
+
+// issueStockedCard marks the physical card carrying the registered number as issued when
+// it is in stock in the registration's organization; numbers without a stocked serial are
+// left alone, so this never fails a registration. Other failures leave the serial in stock
+// and are logged for stock to be corrected by hand
+func (s *CardOwnerService) issueStockedCard(owner *models.CardOwner) {
+	serial := models.NormalizeSerial(owner.CardNumber)
+	move := models.StockMovement{ToState: models.SerialIssued, CardOwnerID: &owner.ID, Note: "registered", UserID: owner.UserID}
+	repo := s.repo.ForTenant(models.Tenant{OrganizationID: owner.OrganizationID})
+	_, err := repo.MoveCardSerials(owner.CardID, []string{serial}, move, func(serial *models.CardSerial) error {
+		if serial.State != models.SerialInStock {
+			return i18n.NewError("serial_not_in_stock", serial.Serial)
+		}
+		return nil
+	})
+
+	var coded *i18n.Error
+	if err == nil || errors.As(err, &coded) && (coded.Code == "serial_not_found" || coded.Code == "serial_not_in_stock") {
+		return
+	}
+	slog.Error("failed to issue stocked card", "card_owner_id", owner.ID, "card_id", owner.CardID, "serial", serial, "error", err)
 }
 
 // RegisterCardOwner creates a new card owner registration
 func (s *CardOwnerService) RegisterCardOwner(userID uint, req *models.RegisterOwnerRequest) (*models.CardOwner, error) {
 	// Check user authentication and active status
-	_, err := s.authService.ValidateUserAccess(userID)
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationID, branchID, err := placement(s.repo, user, nil, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := req.Document().Normalize()
 	if err != nil {
 		return nil, err
 	}
 
 	// Validate duplicate card registration
-	err = s.validateDuplicateCardRegistration(req.CardID, req.CardNumber)
+	cardNumber, err := s.validateDuplicateCardRegistration(req.CardID, req.CardNumber)
 	if err != nil {
+		countDuplicateRejection(err)
 		return nil, err
 	}
+	if cardNumber == "" {
+		cardNumber, err = s.allocateCardNumber(req.CardID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Note: Same owner (ID card) can register multiple different cards
-	// We only prevent duplicate card_number + card_id combinations, not duplicate ID cards
+	// Note: Same owner (identity document) can register multiple different cards
+	// We only prevent duplicate card_number + card_id combinations, not duplicate documents
+	person, err := s.repo.FindOrCreatePerson(doc, req.PhoneNumber)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_register_owner")
+	}
 
 	// Create card owner
 	cardOwner := &models.CardOwner{
-		CardID:      req.CardID,
-		CardNumber:  req.CardNumber,
-		IDCard:      req.IDCard,
-		PhoneNumber: req.PhoneNumber,
-		UserID:      userID,
+		CardID:         req.CardID,
+		CardNumber:     cardNumber,
+		PersonID:       person.ID,
+		UserID:         userID,
+		OrganizationID: organizationID,
+		BranchID:       branchID,
 	}
 
 	err = s.repo.CreateCardOwner(cardOwner)
 	if err != nil {
-		return nil, errors.New("failed to register card owner")
+		return nil, i18n.NewError("failed_to_register_owner")
 	}
+	s.issueStockedCard(cardOwner)
+	metrics.RegistrationsCreated.WithLabelValues(user.Role).Inc()
 
+	cardOwner.SetHolder(person)
 	return cardOwner, nil
 }
 
 // RegisterMultipleCards creates multiple card owner registrations in a single transaction
 func (s *CardOwnerService) RegisterMultipleCards(userID uint, req *models.RegisterMultipleCardsRequest) ([]models.CardOwner, error) {
 	// Check user authentication and active status
-	_, err := s.authService.ValidateUserAccess(userID)
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationID, branchID, err := placement(s.repo, user, nil, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := req.Document().Normalize()
 	if err != nil {
 		return nil, err
 	}
@@ -88,28 +207,48 @@ func (s *CardOwnerService) RegisterMultipleCards(userID uint, req *models.Regist
 	var cardOwners []models.CardOwner
 	
 	// Validate each card and check for duplicates
-	for _, cardReg := range req.Cards {
-		err = s.validateDuplicateCardRegistration(cardReg.CardID, cardReg.CardNumber)
+	cardNumbers := make([]string, len(req.Cards))
+	for i, cardReg := range req.Cards {
+		cardNumbers[i], err = s.validateDuplicateCardRegistration(cardReg.CardID, cardReg.CardNumber)
 		if err != nil {
+			countDuplicateRejection(err)
 			return nil, err
 		}
 	}
 
+	// All cards belong to the same person
+	person, err := s.repo.FindOrCreatePerson(doc, req.PhoneNumber)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_register_owner")
+	}
+
 	// Create all card owner registrations
-	for _, cardReg := range req.Cards {
+	for i, cardReg := range req.Cards {
+		cardNumber := cardNumbers[i]
+		if cardNumber == "" {
+			cardNumber, err = s.allocateCardNumber(cardReg.CardID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		cardOwner := &models.CardOwner{
-			CardID:      cardReg.CardID,
-			CardNumber:  cardReg.CardNumber,
-			IDCard:      req.IDCard,
-			PhoneNumber: req.PhoneNumber,
-			UserID:      userID,
+			CardID:         cardReg.CardID,
+			CardNumber:     cardNumber,
+			PersonID:       person.ID,
+			UserID:         userID,
+			OrganizationID: organizationID,
+			BranchID:       branchID,
 		}
 
 		err = s.repo.CreateCardOwner(cardOwner)
 		if err != nil {
-			return nil, errors.New("failed to register card owner")
+			return nil, i18n.NewError("failed_to_register_owner")
 		}
+		s.issueStockedCard(cardOwner)
+		metrics.RegistrationsCreated.WithLabelValues(user.Role).Inc()
 
+		cardOwner.SetHolder(person)
 		cardOwners = append(cardOwners, *cardOwner)
 	}
 
@@ -124,8 +263,8 @@ func (s *CardOwnerService) GetCardOwnerProfiles(userID uint) ([]models.CardOwner
 		return nil, err
 	}
 
-	// Get all card owners by user ID
-	cardOwners, err := s.repo.GetCardOwnersByUserID(userID)
+	// Get all card owners by user ID; a user's own registrations aren't limited to a tenant
+	cardOwners, err := s.repo.AllTenants().GetCardOwnersByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -150,18 +289,15 @@ func (s *CardOwnerService) GetCardOwnerProfiles(userID uint) ([]models.CardOwner
 // GetAllCardOwners retrieves all card owners (admin only)
 func (s *CardOwnerService) GetAllCardOwners(userID uint) ([]models.CardOwnerWithCard, error) {
 	// Check user authentication and active status (admin only)
-	user, err := s.authService.ValidateUserAccess(userID)
+	user, err := s.authService.requireAdmin(userID)
 	if err != nil {
 		return nil, err
 	}
-	if user.Role != "admin" {
-		return nil, errors.New("insufficient permissions")
-	}
 
-	// Get all card owners
-	cardOwners, err := s.repo.GetAllCardOwners()
+	// Get all card owners in the admin's organization or branch
+	cardOwners, err := s.repo.ForTenant(user.Tenant()).GetAllCardOwners()
 	if err != nil {
-		return nil, errors.New("failed to get card owners")
+		return nil, i18n.NewError("failed_to_get_card_owners")
 	}
 
 	// Populate card master data for each owner
@@ -190,14 +326,14 @@ func (s *CardOwnerService) UpdateCardOwner(userID uint, cardOwnerID uint, req *m
 	}
 
 	// Get existing card owner by ID and verify ownership
-	cardOwner, err := s.repo.GetCardOwnerByID(cardOwnerID)
+	cardOwner, err := s.repo.AllTenants().GetCardOwnerByID(cardOwnerID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify that the card owner belongs to the authenticated user
 	if cardOwner.UserID != userID {
-		return nil, errors.New("card owner not found or access denied")
+		return nil, i18n.NewError("card_owner_access_denied")
 	}
 
 	// Update fields if provided
@@ -211,24 +347,42 @@ func (s *CardOwnerService) UpdateCardOwner(userID uint, cardOwnerID uint, req *m
 
 	// If either CardID or CardNumber is being updated, validate the combination
 	if req.CardID != 0 || req.CardNumber != "" {
-		err = s.validateDuplicateCardRegistration(cardOwner.CardID, cardOwner.CardNumber, cardOwner.ID)
+		cardOwner.CardNumber, err = s.validateDuplicateCardRegistration(cardOwner.CardID, cardOwner.CardNumber, cardOwner.ID)
 		if err != nil {
+			countDuplicateRejection(err)
 			return nil, err
 		}
 	}
 
-	if req.IDCard != "" {
-		// Allow updating ID card (same owner can have multiple registrations)
-		cardOwner.IDCard = req.IDCard
-	}
-
-	if req.PhoneNumber != "" {
-		cardOwner.PhoneNumber = req.PhoneNumber
+	// A different identity document moves the registration to that person; a new phone
+	// number updates the person, so all of their registrations see it
+	if req.IDCard != "" || req.PhoneNumber != "" {
+		doc := models.IdentityDocument{Type: cardOwner.DocumentType, Country: cardOwner.IssuingCountry, Number: cardOwner.IDCard}
+		phoneNumber := req.PhoneNumber
+		if req.IDCard != "" {
+			doc, err = models.IdentityDocument{Type: req.DocumentType, Country: req.IssuingCountry, Number: req.IDCard}.Normalize()
+			if err != nil {
+				return nil, err
+			}
+			if phoneNumber == "" {
+				// Carry the current phone number over to a person who doesn't exist yet
+				if _, err := s.repo.AllTenants().GetPersonByDocument(doc); err != nil {
+					phoneNumber = cardOwner.PhoneNumber
+				}
+			}
+		}
+		person, err := s.repo.FindOrCreatePerson(doc, phoneNumber)
+		if err != nil {
+			return nil, i18n.NewError("failed_to_update_card_owner")
+		}
+		cardOwner.PersonID = person.ID
+		cardOwner.Person = person
+		cardOwner.SetHolder(person)
 	}
 
 	err = s.repo.UpdateCardOwner(cardOwner)
 	if err != nil {
-		return nil, errors.New("failed to update card owner")
+		return nil, i18n.NewError("failed_to_update_card_owner")
 	}
 
 	return cardOwner, nil
@@ -243,14 +397,14 @@ func (s *CardOwnerService) DeleteCardOwner(userID uint, cardOwnerID uint) error
 	}
 
 	// Get existing card owner by ID and verify ownership
-	cardOwner, err := s.repo.GetCardOwnerByID(cardOwnerID)
+	cardOwner, err := s.repo.AllTenants().GetCardOwnerByID(cardOwnerID)
 	if err != nil {
 		return err
 	}
 
 	// Verify that the card owner belongs to the authenticated user
 	if cardOwner.UserID != userID {
-		return errors.New("card owner not found or access denied")
+		return i18n.NewError("card_owner_access_denied")
 	}
 
 	return s.repo.DeleteCardOwner(cardOwner.ID)
@@ -259,13 +413,15 @@ func (s *CardOwnerService) DeleteCardOwner(userID uint, cardOwnerID uint) error
 // ValidateDuplicateCardRegistration is a public service for validating duplicate card registration
 // This can be used by external services or handlers to check for duplicates before registration
 func (s *CardOwnerService) ValidateDuplicateCardRegistration(cardID uint, cardNumber string) error {
-	return s.validateDuplicateCardRegistration(cardID, cardNumber)
+	_, err := s.validateDuplicateCardRegistration(cardID, cardNumber)
+	return err
 }
 
 // ValidateDuplicateCardRegistrationForUpdate is a public service for validating duplicate card registration during updates
 // excludeID should be the ID of the card owner being updated
 func (s *CardOwnerService) ValidateDuplicateCardRegistrationForUpdate(cardID uint, cardNumber string, excludeID uint) error {
-	return s.validateDuplicateCardRegistration(cardID, cardNumber, excludeID)
+	_, err := s.validateDuplicateCardRegistration(cardID, cardNumber, excludeID)
+	return err
 }
 
 // SearchCardOwnersByCardNameAndNumber searches for card owners by card name and card number
@@ -279,15 +435,16 @@ func (s *CardOwnerService) SearchCardOwnersByCardNameAndNumber(userID uint, card
 
 	var result []models.CardOwnerWithCard
 	
-	// If admin, search across all card owners; if regular user, search only their own registrations
+	// Admins and API clients (scope checked by middleware) search across all card owners
+	// of their tenant; regular users search only their own registrations
 	var cardOwners []models.CardOwner
-	if user.Role == "admin" {
-		cardOwners, err = s.repo.GetAllCardOwners()
+	if user.IsAdmin() || user.Role == models.RoleAPIClient {
+		cardOwners, err = s.repo.ForTenant(user.Tenant()).GetAllCardOwners()
 		if err != nil {
-			return nil, errors.New("failed to get card owners")
+			return nil, i18n.NewError("failed_to_get_card_owners")
 		}
 	} else {
-		cardOwners, err = s.repo.GetCardOwnersByUserID(userID)
+		cardOwners, err = s.repo.AllTenants().GetCardOwnersByUserID(userID)
 		if err != nil {
 			return nil, err
 		}
@@ -322,9 +479,11 @@ func (s *CardOwnerService) SearchCardOwnersByCardNameAndNumber(userID uint, card
 	return result, nil
 }
 
-// SearchCardOwnersByIDCardOrPhone searches for card owners by ID card or phone number
+// SearchCardOwnersByIDCardOrPhone searches for card owners by document number or phone number
 // This service allows searching for card owners using their personal information
-func (s *CardOwnerService) SearchCardOwnersByIDCardOrPhone(userID uint, idCard string, phoneNumber string) ([]models.CardOwnerWithCard, error) {
+// The document number matches national IDs, passports and other documents alike;
+// documentType and issuingCountry optionally narrow the results
+func (s *CardOwnerService) SearchCardOwnersByIDCardOrPhone(userID uint, idCard string, phoneNumber string, documentType string, issuingCountry string) ([]models.CardOwnerWithCard, error) {
 	// Check user authentication and active status
 	user, err := s.authService.ValidateUserAccess(userID)
 	if err != nil {
@@ -333,31 +492,39 @@ func (s *CardOwnerService) SearchCardOwnersByIDCardOrPhone(userID uint, idCard s
 
 	// At least one search parameter must be provided
 	if (idCard == "" || len(idCard) == 0) && (phoneNumber == "" || len(phoneNumber) == 0) {
-		return nil, errors.New("at least one search parameter (ID card or phone number) must be provided")
+		return nil, i18n.NewError("owner_search_required")
 	}
 
 	var result []models.CardOwnerWithCard
 	
-	// If admin, search across all card owners; if regular user, search only their own registrations
+	// Admins and API clients (scope checked by middleware) search across all card owners
+	// of their tenant; regular users search only their own registrations
 	var cardOwners []models.CardOwner
-	if user.Role == "admin" {
-		cardOwners, err = s.repo.GetAllCardOwners()
+	if user.IsAdmin() || user.Role == models.RoleAPIClient {
+		cardOwners, err = s.repo.ForTenant(user.Tenant()).GetAllCardOwners()
 		if err != nil {
-			return nil, errors.New("failed to get card owners")
+			return nil, i18n.NewError("failed_to_get_card_owners")
 		}
 	} else {
-		cardOwners, err = s.repo.GetCardOwnersByUserID(userID)
+		cardOwners, err = s.repo.AllTenants().GetCardOwnersByUserID(userID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// Filter by ID card or phone number
+	// Filter by document number or phone number
 	for _, owner := range cardOwners {
-		// Check if ID card matches (partial match, case-insensitive)
+		if documentType != "" && !strings.EqualFold(owner.DocumentType, documentType) {
+			continue
+		}
+		if issuingCountry != "" && !strings.EqualFold(owner.IssuingCountry, issuingCountry) {
+			continue
+		}
+
+		// Check if document number matches (partial match, case-insensitive)
 		idCardMatches := idCard == "" || 
 			len(idCard) == 0 || 
-			strings.Contains(strings.ToLower(owner.IDCard), strings.ToLower(idCard))
+			strings.Contains(owner.IDCard, models.NormalizeDocumentNumber(idCard))
 
 		// Check if phone number matches (partial match)
 		phoneMatches := phoneNumber == "" || 
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"tiger-fasttrack-card/internal/cardnumber"
+	"tiger-fasttrack-card/internal/i18n"
 	"tiger-fasttrack-card/internal/models"
 	"tiger-fasttrack-card/internal/repository"
 )
@@ -18,15 +22,31 @@ func NewCardService(repo *repository.Repository, authService *AuthService) *Card
 	}
 }
 
+// withContext returns a copy whose queries run with ctx
+func (s *CardService) withContext(ctx context.Context) *CardService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
 // Card service methods
-func (s *CardService) GetAllCards(userID uint) ([]models.Card, error) {
+
+// GetAllCards returns the cards matching filter in display order
+// Only admins can list inactive cards; everyone else always gets active cards only
+func (s *CardService) GetAllCards(userID uint, filter models.CardFilter) ([]models.Card, error) {
 	// Verify user exists and is active
-	_, err := s.authService.ValidateUserAccess(userID)
+	user, err := s.authService.ValidateUserAccess(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.GetAllCards()
+	if !user.IsAdmin() {
+		active := true
+		filter.IsActive = &active
+	}
+
+	return s.repo.GetCards(filter)
 }
 
 func (s *CardService) GetCardByID(userID uint, id uint) (*models.Card, error) {
@@ -39,37 +59,63 @@ func (s *CardService) GetCardByID(userID uint, id uint) (*models.Card, error) {
 	return s.repo.GetCardByID(id)
 }
 
+// CreateCard adds a card to the master data (super admin only)
 func (s *CardService) CreateCard(userID uint, req *models.CreateCardRequest) (*models.Card, error) {
-	// Verify user exists and is active
-	_, err := s.authService.ValidateUserAccess(userID)
-	if err != nil {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
 		return nil, err
 	}
 
-	// Validate card quantity
-	if req.CardQuantity < 0 {
-		return nil, errors.New("card quantity cannot be negative")
-	}
-
-	// Create card
-	card := &models.Card{
-		CardName:     req.CardName,
-		CardImage:    req.CardImage,
-		CardQuantity: req.CardQuantity,
+	card, err := newCard(req)
+	if err != nil {
+		return nil, err
 	}
 
 	err = s.repo.CreateCard(card)
 	if err != nil {
-		return nil, errors.New("failed to create card")
+		return nil, i18n.NewError("failed_to_create_card")
+	}
+
+	return card, nil
+}
+
+// newCard validates a card request and returns the unsaved card
+func newCard(req *models.CreateCardRequest) (*models.Card, error) {
+	if req.Price < 0 {
+		return nil, i18n.NewError("price_negative")
 	}
 
+	card := &models.Card{
+		CardName:      req.CardName,
+		CardNameTH:    req.CardNameTH,
+		CardImage:     req.CardImage,
+		Category:      strings.TrimSpace(req.Category),
+		Tier:          strings.TrimSpace(req.Tier),
+		Price:         req.Price,
+		Currency:      "THB",
+		Benefits:      cleanBenefits(req.Benefits),
+		DescriptionTH: req.DescriptionTH,
+		DescriptionEN: req.DescriptionEN,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+		DisplayOrder:  req.DisplayOrder,
+		NumberPrefix:  req.NumberPrefix,
+		NumberLength:  req.NumberLength,
+		CheckDigit:    cardnumber.CheckDigitNone,
+	}
+	if req.CheckDigit != "" {
+		card.CheckDigit = strings.ToLower(req.CheckDigit)
+	}
+	if err := card.NumberScheme().Validate(); err != nil {
+		return nil, err
+	}
+	if req.Currency != "" {
+		card.Currency = strings.ToUpper(req.Currency)
+	}
 	return card, nil
 }
 
+// UpdateCard changes the fields given in req (super admin only)
 func (s *CardService) UpdateCard(userID uint, id uint, req *models.UpdateCardRequest) (*models.Card, error) {
-	// Verify user exists and is active
-	_, err := s.authService.ValidateUserAccess(userID)
-	if err != nil {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
 		return nil, err
 	}
 
@@ -83,36 +129,171 @@ func (s *CardService) UpdateCard(userID uint, id uint, req *models.UpdateCardReq
 	if req.CardName != "" {
 		card.CardName = req.CardName
 	}
+	if req.CardNameTH != nil {
+		card.CardNameTH = *req.CardNameTH
+	}
 	if req.CardImage != "" {
 		card.CardImage = req.CardImage
 	}
-	if req.CardQuantity != nil {
-		if *req.CardQuantity < 0 {
-			return nil, errors.New("card quantity cannot be negative")
+	if req.Category != nil {
+		card.Category = strings.TrimSpace(*req.Category)
+	}
+	if req.Tier != nil {
+		card.Tier = strings.TrimSpace(*req.Tier)
+	}
+	if req.Price != nil {
+		if *req.Price < 0 {
+			return nil, i18n.NewError("price_negative")
 		}
-		card.CardQuantity = *req.CardQuantity
+		card.Price = *req.Price
+	}
+	if req.Currency != "" {
+		card.Currency = strings.ToUpper(req.Currency)
+	}
+	if req.Benefits != nil {
+		card.Benefits = cleanBenefits(req.Benefits)
+	}
+	if req.DescriptionTH != nil {
+		card.DescriptionTH = *req.DescriptionTH
+	}
+	if req.DescriptionEN != nil {
+		card.DescriptionEN = *req.DescriptionEN
+	}
+	if req.IsActive != nil {
+		card.IsActive = *req.IsActive
+	}
+	if req.DisplayOrder != nil {
+		card.DisplayOrder = *req.DisplayOrder
+	}
+	if req.NumberPrefix != nil {
+		card.NumberPrefix = *req.NumberPrefix
+	}
+	if req.NumberLength != nil {
+		card.NumberLength = *req.NumberLength
+	}
+	if req.CheckDigit != "" {
+		card.CheckDigit = strings.ToLower(req.CheckDigit)
+	}
+	if err := card.NumberScheme().Validate(); err != nil {
+		return nil, err
 	}
 
 	err = s.repo.UpdateCard(card)
 	if err != nil {
-		return nil, errors.New("failed to update card")
+		return nil, i18n.NewError("failed_to_update_card")
 	}
 
 	return card, nil
 }
 
+// DeleteCard removes a card from the master data (super admin only)
 func (s *CardService) DeleteCard(userID uint, id uint) error {
-	// Verify user exists and is active
-	_, err := s.authService.ValidateUserAccess(userID)
-	if err != nil {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
 		return err
 	}
 
 	// Check if card exists
-	_, err = s.repo.GetCardByID(id)
+	_, err := s.repo.GetCardByID(id)
 	if err != nil {
 		return err
 	}
 
 	return s.repo.DeleteCard(id)
 }
+
+// CreateNumberRange pre-generates a block of card numbers for printed stock (super admin only)
+// The numbers are taken from the same sequence as allocated numbers, so they never collide
+func (s *CardService) CreateNumberRange(userID uint, cardID uint, req *models.CreateCardNumberRangeRequest) (*models.CardNumberRange, error) {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
+		return nil, err
+	}
+	if req.Count < 1 || req.Count > models.MaxCardNumberRange {
+		return nil, i18n.NewError("invalid_range_count", models.MaxCardNumberRange)
+	}
+
+	card, err := s.repo.GetCardByID(cardID)
+	if err != nil {
+		return nil, err
+	}
+	if !card.NumberScheme().Enabled() {
+		return nil, i18n.NewError("card_no_number_scheme")
+	}
+
+	rng := &models.CardNumberRange{
+		CardID:      cardID,
+		Count:       req.Count,
+		Note:        req.Note,
+		CreatedByID: userID,
+	}
+	err = s.repo.CreateCardNumberRange(rng, func(card *models.Card) error {
+		rng.NumberPrefix = card.NumberPrefix
+		rng.NumberLength = card.NumberLength
+		rng.CheckDigit = card.CheckDigit
+		numbers, err := rangeNumbers(rng)
+		if err != nil {
+			return err
+		}
+		rng.Numbers = numbers
+		rng.FirstNumber = numbers[0]
+		rng.LastNumber = numbers[len(numbers)-1]
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, cardnumber.ErrExhausted) {
+			return nil, err
+		}
+		return nil, i18n.NewError("failed_to_create_card_number_range")
+	}
+	return rng, nil
+}
+
+// ListNumberRanges returns the card's pre-generated ranges without their numbers (super admin only)
+func (s *CardService) ListNumberRanges(userID uint, cardID uint) ([]models.CardNumberRange, error) {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCardNumberRanges(cardID)
+}
+
+// GetNumberRange returns a pre-generated range with all of its numbers (super admin only)
+func (s *CardService) GetNumberRange(userID uint, cardID uint, rangeID uint) (*models.CardNumberRange, error) {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
+		return nil, err
+	}
+
+	rng, err := s.repo.GetCardNumberRange(cardID, rangeID)
+	if err != nil {
+		return nil, err
+	}
+	rng.Numbers, err = rangeNumbers(rng)
+	if err != nil {
+		return nil, err
+	}
+	return rng, nil
+}
+
+// rangeNumbers formats the range's sequences with the scheme it was generated with
+func rangeNumbers(rng *models.CardNumberRange) ([]string, error) {
+	scheme := rng.NumberScheme()
+	numbers := make([]string, 0, rng.Count)
+	for seq := rng.FirstSequence; seq <= rng.LastSequence; seq++ {
+		number, err := scheme.Format(seq)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// cleanBenefits trims the benefit entries and drops empty ones; the result is never nil
+// so the column always holds a JSON array
+func cleanBenefits(benefits []string) []string {
+	cleaned := []string{}
+	for _, benefit := range benefits {
+		if benefit = strings.TrimSpace(benefit); benefit != "" {
+			cleaned = append(cleaned, benefit)
+		}
+	}
+	return cleaned
+}
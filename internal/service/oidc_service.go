@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/oidc"
+	"tiger-fasttrack-card/internal/repository"
+)
+
+// ErrSSODisabled is returned when no identity provider is configured
+var ErrSSODisabled = i18n.NewError("sso_disabled")
+
+// RoleMapping grants Role to users whose IdP groups include Group
+type RoleMapping struct {
+	Group string
+	Role  string
+}
+
+// OIDCOptions configures single sign-on for staff
+type OIDCOptions struct {
+	Provider                *oidc.Provider // nil disables single sign-on
+	RoleMapping             []RoleMapping  // first matching group wins
+	DefaultRole             string         // role when no group matches; empty denies access
+	LinkLocalAccounts       bool           // attach the IdP identity to an existing local account with the same username
+	LocalLoginDisabledRoles []string       // roles that can't log in with a password
+}
+
+// OIDCService handles OpenID Connect login with just-in-time user provisioning
+type OIDCService struct {
+	repo              *repository.Repository
+	authService       *AuthService
+	provider          *oidc.Provider
+	roleMapping       []RoleMapping
+	defaultRole       string
+	linkLocalAccounts bool
+}
+
+// NewOIDCService creates a new OIDCService instance
+func NewOIDCService(repo *repository.Repository, authService *AuthService, opts OIDCOptions) *OIDCService {
+	return &OIDCService{
+		repo:              repo,
+		authService:       authService,
+		provider:          opts.Provider,
+		roleMapping:       opts.RoleMapping,
+		defaultRole:       opts.DefaultRole,
+		linkLocalAccounts: opts.LinkLocalAccounts,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *OIDCService) withContext(ctx context.Context) *OIDCService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// BeginLogin starts an authorization code flow; the returned flow must be kept
+// by the client (cookie) and handed back to CompleteLogin
+func (s *OIDCService) BeginLogin() (*oidc.Flow, string, error) {
+	if s.provider == nil {
+		return nil, "", ErrSSODisabled
+	}
+	flow, err := oidc.NewFlow()
+	if err != nil {
+		return nil, "", i18n.NewError("sso_start_failed")
+	}
+	return flow, s.provider.AuthCodeURL(flow), nil
+}
+
+// CompleteLogin exchanges the authorization code, provisions or updates the user and
+// issues tokens. Second factors are left to the identity provider.
+func (s *OIDCService) CompleteLogin(ctx context.Context, code string, flow *oidc.Flow) (*models.LoginResponse, error) {
+	if s.provider == nil {
+		return nil, ErrSSODisabled
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, flow)
+	if err != nil {
+		slog.WarnContext(ctx, "oidc login failed", "error", err)
+		return nil, i18n.NewError("sso_failed")
+	}
+
+	role := s.mapRole(identity.Groups)
+	if role == "" {
+		return nil, i18n.NewError("account_not_authorized")
+	}
+
+	user, err := s.repo.GetUserByOIDCSubject(identity.Subject)
+	if err != nil {
+		user, err = s.provisionUser(identity, role)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := s.syncUser(user, identity, role); err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, i18n.NewError("account_deactivated")
+	}
+
+	return s.authService.issueTokens(user)
+}
+
+// provisionUser creates the account on first login, or links a local account when allowed
+func (s *OIDCService) provisionUser(identity *oidc.Identity, role string) (*models.User, error) {
+	username := identity.Username
+	if username == "" {
+		username = identity.Email
+	}
+	if username == "" {
+		username = "oidc:" + identity.Subject
+	}
+
+	existingUser, _ := s.repo.GetUserByUsername(username)
+	if existingUser != nil {
+		if !s.linkLocalAccounts || existingUser.SSO() || existingUser.Role == models.RoleAPIClient {
+			return nil, i18n.NewError("username_in_use")
+		}
+		subject := identity.Subject
+		existingUser.OIDCSubject = &subject
+		if err := s.syncUser(existingUser, identity, role); err != nil {
+			return nil, err
+		}
+		slog.Info("linked local user to identity provider subject", "user_id", existingUser.ID)
+		return existingUser, nil
+	}
+
+	// The password is random and never disclosed; SSO accounts can't log in locally anyway
+	unusablePassword, err := randomHex(32)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_create_user")
+	}
+	unusableHash, err := s.authService.passwords.Hash(unusablePassword)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_create_user")
+	}
+
+	subject := identity.Subject
+	user := &models.User{
+		Username:    username,
+		Password:    unusableHash,
+		FirstName:   identity.FirstName,
+		LastName:    identity.LastName,
+		IsActive:    true,
+		Role:        role,
+		OIDCSubject: &subject,
+	}
+	if s.emailAvailable(identity.Email, 0) {
+		user.Email = identity.Email
+	}
+	// Staff start in the default organization; admins can move them to another tenant
+	if !user.IsSuperAdmin() {
+		org, err := s.repo.GetOrganizationByCode(models.DefaultOrganizationCode)
+		if err != nil {
+			return nil, i18n.NewError("failed_to_create_user")
+		}
+		user.OrganizationID = &org.ID
+	}
+
+	if err := s.repo.CreateUser(user); err != nil {
+		return nil, i18n.NewError("failed_to_create_user")
+	}
+	return user, nil
+}
+
+// syncUser applies the IdP's current role and profile; the IdP is authoritative for SSO accounts
+func (s *OIDCService) syncUser(user *models.User, identity *oidc.Identity, role string) error {
+	user.Role = role
+	if identity.FirstName != "" {
+		user.FirstName = identity.FirstName
+	}
+	if identity.LastName != "" {
+		user.LastName = identity.LastName
+	}
+	if identity.Email != "" && s.emailAvailable(identity.Email, user.ID) {
+		user.Email = identity.Email
+	}
+	if err := s.repo.UpdateUser(user); err != nil {
+		return i18n.NewError("failed_to_update_user")
+	}
+	return nil
+}
+
+// emailAvailable reports whether email isn't registered to a user other than userID
+func (s *OIDCService) emailAvailable(email string, userID uint) bool {
+	if email == "" {
+		return false
+	}
+	existingUser, _ := s.repo.GetUserByEmail(email)
+	return existingUser == nil || existingUser.ID == userID
+}
+
+// mapRole returns the role of the first mapping whose group the user belongs to
+func (s *OIDCService) mapRole(groups []string) string {
+	for _, mapping := range s.roleMapping {
+		if containsString(groups, mapping.Group) {
+			return mapping.Role
+		}
+	}
+	return s.defaultRole
+}
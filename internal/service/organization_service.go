@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+)
+
+// OrganizationService manages tenants and which tenant each user belongs to
+type OrganizationService struct {
+	repo        *repository.Repository
+	authService *AuthService
+}
+
+// NewOrganizationService creates a new OrganizationService instance
+func NewOrganizationService(repo *repository.Repository, authService *AuthService) *OrganizationService {
+	return &OrganizationService{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *OrganizationService) withContext(ctx context.Context) *OrganizationService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// CreateOrganization adds a tenant (super admin only)
+func (s *OrganizationService) CreateOrganization(userID uint, req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if existing, _ := s.repo.GetOrganizationByCode(code); existing != nil {
+		return nil, i18n.NewError("organization_code_exists", code)
+	}
+	orgType, err := organizationType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	org := &models.Organization{
+		Code:     code,
+		Name:     strings.TrimSpace(req.Name),
+		Type:     orgType,
+		IsActive: true,
+	}
+	if err := s.repo.CreateOrganization(org); err != nil {
+		return nil, i18n.NewError("failed_to_create_organization")
+	}
+	return org, nil
+}
+
+// ListOrganizations returns every organization (super admin only)
+func (s *OrganizationService) ListOrganizations(userID uint) ([]models.Organization, error) {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrganizations()
+}
+
+// UpdateOrganization renames, retypes or (de)activates an organization (super admin only)
+// Inactive organizations can't be given new users, branches, registrations or stock
+func (s *OrganizationService) UpdateOrganization(userID uint, id uint, req *models.UpdateOrganizationRequest) (*models.Organization, error) {
+	if _, err := s.authService.requireSuperAdmin(userID); err != nil {
+		return nil, err
+	}
+
+	org, err := s.repo.GetOrganizationByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		org.Name = name
+	}
+	if req.Type != "" {
+		if org.Type, err = organizationType(req.Type); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		org.IsActive = *req.IsActive
+	}
+	if err := s.repo.UpdateOrganization(org); err != nil {
+		return nil, i18n.NewError("failed_to_update_organization")
+	}
+	return org, nil
+}
+
+// AssignUserTenant moves a user to an organization and branch (admin only)
+// Admins move users within their own organization; super admins move them anywhere
+func (s *OrganizationService) AssignUserTenant(adminID uint, targetUserID uint, req *models.AssignTenantRequest) (*models.User, error) {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin.Tenant().Contains(tenantOf(user)) {
+		return nil, i18n.NewError("user_not_found")
+	}
+	if user.IsSuperAdmin() {
+		return nil, i18n.NewError("super_admin_no_organization")
+	}
+
+	organizationID := req.OrganizationID
+	if organizationID == nil {
+		organizationID = user.OrganizationID
+	}
+	orgID, branchID, err := placement(s.repo, admin, organizationID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.OrganizationID = &orgID
+	user.BranchID = branchID
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, i18n.NewError("failed_to_update_user")
+	}
+	return user, nil
+}
+
+// placement picks the organization and branch for data the user creates. A given branch must
+// be an active branch the user can see; only super admins may name another organization.
+// Without either, the data goes to the user's own organization and branch, or to the default
+// organization for super admins
+func placement(repo *repository.Repository, user *models.User, organizationID *uint, branchID *uint) (uint, *uint, error) {
+	tenant := user.Tenant()
+
+	if organizationID != nil && !tenant.All && *organizationID != tenant.OrganizationID {
+		return 0, nil, ErrInsufficientPermissions
+	}
+
+	if branchID != nil {
+		branch, err := repo.ForTenant(tenant).GetBranchByID(*branchID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !branch.IsActive {
+			return 0, nil, i18n.NewError("branch_not_active")
+		}
+		if organizationID != nil && *organizationID != branch.OrganizationID {
+			return 0, nil, i18n.NewError("branch_other_organization")
+		}
+		if err := activeOrganization(repo, branch.OrganizationID); err != nil {
+			return 0, nil, err
+		}
+		return branch.OrganizationID, &branch.ID, nil
+	}
+
+	if organizationID != nil {
+		if err := activeOrganization(repo, *organizationID); err != nil {
+			return 0, nil, err
+		}
+		if tenant.All {
+			return *organizationID, nil, nil
+		}
+		return *organizationID, tenant.BranchID, nil
+	}
+
+	if !tenant.All {
+		if err := activeOrganization(repo, tenant.OrganizationID); err != nil {
+			return 0, nil, err
+		}
+		return tenant.OrganizationID, tenant.BranchID, nil
+	}
+	org, err := repo.GetOrganizationByCode(models.DefaultOrganizationCode)
+	if err != nil {
+		return 0, nil, err
+	}
+	return org.ID, nil, nil
+}
+
+func activeOrganization(repo *repository.Repository, id uint) error {
+	org, err := repo.GetOrganizationByID(id)
+	if err != nil {
+		return err
+	}
+	if !org.IsActive {
+		return i18n.NewError("organization_not_active")
+	}
+	return nil
+}
+
+// tenantOf returns the organization and branch a user belongs to, for Tenant.Contains
+func tenantOf(user *models.User) (uint, *uint) {
+	if user.OrganizationID == nil {
+		return 0, user.BranchID
+	}
+	return *user.OrganizationID, user.BranchID
+}
+
+// organizationType validates an organization type, defaulting to company
+func organizationType(orgType string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(orgType)) {
+	case "", models.OrganizationCompany:
+		return models.OrganizationCompany, nil
+	case models.OrganizationAgency:
+		return models.OrganizationAgency, nil
+	}
+	return "", i18n.NewError("invalid_organization_type")
+}
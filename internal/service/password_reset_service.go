@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/notify"
+	"tiger-fasttrack-card/internal/repository"
+	"time"
+)
+
+// ErrPasswordResetUnavailable is returned when no notifier or reset page is configured to
+// deliver reset tokens
+var ErrPasswordResetUnavailable = i18n.NewError("password_reset_unavailable")
+
+// PasswordResetService handles the forgot-password / reset-password flow
+type PasswordResetService struct {
+	repo        *repository.Repository
+	authService *AuthService
+	notifier    notify.Notifier // nil disables password reset
+	tokenTTL    time.Duration
+	resetURL    string
+}
+
+// NewPasswordResetService creates a new PasswordResetService instance
+// resetURL is the page that accepts the token; the token is appended as ?token=
+func NewPasswordResetService(repo *repository.Repository, authService *AuthService, notifier notify.Notifier, tokenTTL time.Duration, resetURL string) *PasswordResetService {
+	return &PasswordResetService{
+		repo:        repo,
+		authService: authService,
+		notifier:    notifier,
+		tokenTTL:    tokenTTL,
+		resetURL:    resetURL,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *PasswordResetService) withContext(ctx context.Context) *PasswordResetService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// RequestPasswordReset issues a reset token and sends it to the user's email
+// It never reports whether the account exists; unknown users and users without
+// an email address are silently ignored
+func (s *PasswordResetService) RequestPasswordReset(req *models.ForgotPasswordRequest) error {
+	if s.notifier == nil || s.resetURL == "" {
+		return ErrPasswordResetUnavailable
+	}
+	if req.Username == "" && req.Email == "" {
+		return i18n.NewError("username_or_email_required")
+	}
+
+	var user *models.User
+	if req.Email != "" {
+		user, _ = s.repo.GetUserByEmail(req.Email)
+	} else {
+		user, _ = s.repo.GetUserByUsername(req.Username)
+	}
+	if user == nil || !user.IsActive || user.Email == "" || !s.authService.allowsLocalLogin(user) {
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return i18n.NewError("failed_to_generate_reset")
+	}
+
+	err = s.repo.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+	if err != nil {
+		return i18n.NewError("failed_to_create_reset")
+	}
+
+	msg := notify.Message{
+		To:      user.Email,
+		Subject: "Reset your Tiger FastTrack Card password",
+		Body:    s.resetMessage(user, token),
+	}
+
+	// Send in the background so response time doesn't reveal whether the account exists
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.notifier.Send(ctx, msg); err != nil {
+			slog.Error("failed to send password reset", "user_id", user.ID, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token
+// The token is consumed, other outstanding tokens are invalidated and all
+// refresh tokens issued before the reset stop working
+func (s *PasswordResetService) ResetPassword(req *models.ResetPasswordRequest) error {
+	resetToken, err := s.repo.GetPasswordResetTokenByHash(hashResetToken(req.Token))
+	if err != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return i18n.NewError("invalid_reset_token")
+	}
+
+	user, err := s.authService.ValidateUserAccess(resetToken.UserID)
+	if err != nil || !s.authService.allowsLocalLogin(user) {
+		return i18n.NewError("invalid_reset_token")
+	}
+
+	// Validate before consuming so a weak password doesn't burn the token
+	if err := s.authService.passwords.Validate(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+	if err := s.authService.checkPasswordReuse(user, req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.authService.passwords.Hash(req.NewPassword)
+	if err != nil {
+		return i18n.NewError("failed_to_hash_new_password")
+	}
+
+	consumed, err := s.repo.ConsumePasswordResetToken(resetToken.ID)
+	if err != nil {
+		return i18n.NewError("failed_to_reset_password")
+	}
+	if !consumed {
+		return i18n.NewError("invalid_reset_token")
+	}
+
+	previousHash := user.Password
+	user.Password = hashedPassword
+	user.TokenVersion++
+	if err := s.repo.UpdateUser(user); err != nil {
+		return i18n.NewError("failed_to_reset_password")
+	}
+
+	s.authService.recordPasswordHistory(user.ID, previousHash)
+
+	if err := s.repo.InvalidatePasswordResetTokens(user.ID); err != nil {
+		slog.Error("failed to invalidate reset tokens", "user_id", user.ID, "error", err)
+	}
+	if err := s.authService.loginGuard.Unlock(user.Username); err != nil {
+		slog.Error("failed to clear login lockout", "user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+func (s *PasswordResetService) resetMessage(user *models.User, token string) string {
+	separator := "?"
+	if strings.Contains(s.resetURL, "?") {
+		separator = "&"
+	}
+	link := s.resetURL + separator + "token=" + token
+
+	return fmt.Sprintf(`Hello %s,
+
+We received a request to reset the password for your Tiger FastTrack Card account (%s).
+
+Use the link below to choose a new password. It expires in %s and can only be used once.
+
+%s
+
+If you did not request a password reset you can ignore this email.
+`, user.FirstName, user.Username, s.tokenTTL, link)
+}
+
+// generateResetToken returns a random URL-safe token
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashResetToken returns the value stored in the database for a token
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+)
+
+// ErrPersonSharedWithOtherTenant is returned when an admin changes a person who also holds
+// cards outside the admin's tenant
+var ErrPersonSharedWithOtherTenant = i18n.NewError("person_shared")
+
+// PersonService handles card holders and their contact details
+type PersonService struct {
+	repo        *repository.Repository
+	authService *AuthService
+}
+
+// NewPersonService creates a new PersonService instance
+func NewPersonService(repo *repository.Repository, authService *AuthService) *PersonService {
+	return &PersonService{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *PersonService) withContext(ctx context.Context) *PersonService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// GetPerson returns a person with their card registrations
+// Admins and API clients see every registration in their tenant; regular users only see
+// people they registered cards for, and only their own registrations
+func (s *PersonService) GetPerson(userID uint, personID uint) (*models.Person, error) {
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.repo.ForTenant(user.Tenant()).GetPersonByID(personID)
+	if err != nil {
+		return nil, err
+	}
+	return s.visiblePerson(user, person)
+}
+
+// GetPersonByDocument looks a person up by identity document, with the same visibility as GetPerson
+// The document type defaults to a national ID issued in Thailand
+func (s *PersonService) GetPersonByDocument(userID uint, doc models.IdentityDocument) (*models.Person, error) {
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = doc.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.repo.ForTenant(user.Tenant()).GetPersonByDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	return s.visiblePerson(user, person)
+}
+
+// ListPeople returns the people with cards in the admin's tenant, without their cards (admin only)
+func (s *PersonService) ListPeople(userID uint) ([]models.Person, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	people, err := s.repo.ForTenant(user.Tenant()).GetAllPeople()
+	if err != nil {
+		return nil, i18n.NewError("failed_to_get_people")
+	}
+	return people, nil
+}
+
+// UpdatePerson changes a person's contact details; the change applies to all of
+// their card registrations, so only admins may make it, and only super admins when the
+// person also holds cards in another tenant (admin only)
+func (s *PersonService) UpdatePerson(userID uint, personID uint, req *models.UpdatePersonRequest) (*models.Person, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.repo.ForTenant(user.Tenant()).GetPersonByID(personID)
+	if err != nil {
+		return nil, err
+	}
+	person, err = s.visiblePerson(user, person)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsSuperAdmin() {
+		everywhere, err := s.repo.AllTenants().GetPersonByID(personID)
+		if err != nil {
+			return nil, err
+		}
+		if len(everywhere.Cards) != len(person.Cards) {
+			return nil, ErrPersonSharedWithOtherTenant
+		}
+	}
+
+	if req.PhoneNumber != "" {
+		person.PhoneNumber = req.PhoneNumber
+	}
+	if req.FirstName != "" {
+		person.FirstName = req.FirstName
+	}
+	if req.LastName != "" {
+		person.LastName = req.LastName
+	}
+	if req.Email != "" {
+		person.Email = req.Email
+	}
+
+	if err := s.repo.UpdatePerson(person); err != nil {
+		return nil, i18n.NewError("failed_to_update_person")
+	}
+
+	fillCardHolder(person)
+	return person, nil
+}
+
+// visiblePerson trims the person's cards, already limited to the user's tenant, to what the
+// user may see; people without a visible card are hidden from everyone but super admins
+func (s *PersonService) visiblePerson(user *models.User, person *models.Person) (*models.Person, error) {
+	if !user.IsAdmin() && user.Role != models.RoleAPIClient {
+		var own []models.CardOwner
+		for _, card := range person.Cards {
+			if card.UserID == user.ID {
+				own = append(own, card)
+			}
+		}
+		if len(own) == 0 {
+			return nil, i18n.NewError("person_access_denied")
+		}
+		person.Cards = own
+	} else if len(person.Cards) == 0 && !user.IsSuperAdmin() {
+		return nil, i18n.NewError("person_access_denied")
+	}
+
+	fillCardHolder(person)
+	return person, nil
+}
+
+// fillCardHolder sets the compatibility fields on cards loaded through their person
+func fillCardHolder(person *models.Person) {
+	for i := range person.Cards {
+		person.Cards[i].SetHolder(person)
+	}
+}
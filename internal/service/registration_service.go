@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"tiger-fasttrack-card/internal/captcha"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/notify"
+	"tiger-fasttrack-card/internal/repository"
+	"time"
+)
+
+// Registration modes
+const (
+	RegistrationOpen   = "open"
+	RegistrationClosed = "closed"
+	RegistrationInvite = "invite"
+)
+
+// Errors returned by Register that map to specific HTTP statuses
+var (
+	ErrRegistrationClosed = i18n.NewError("registration_closed")
+	ErrInvalidInvitation  = i18n.NewError("invalid_invitation")
+)
+
+// maxVerificationAttempts is how many wrong codes invalidate a verification code
+const maxVerificationAttempts = 5
+
+// resendInterval is the minimum time between two verification codes for a user
+const resendInterval = time.Minute
+
+// RegistrationOptions configures self-service registration
+type RegistrationOptions struct {
+	Mode            string           // open, closed or invite
+	Verification    string           // "", models.VerificationEmail or models.VerificationPhone
+	VerificationTTL time.Duration    // how long a verification code is valid
+	InvitationTTL   time.Duration    // default invitation lifetime
+	Captcha         captcha.Verifier // nil disables the CAPTCHA check
+	EmailNotifier   notify.Notifier  // delivers email verification codes and invitations; nil when not configured
+	SMSNotifier     notify.Notifier  // delivers phone verification codes; nil when not configured
+}
+
+// RegistrationService handles public registration, invitations and account verification
+type RegistrationService struct {
+	repo        *repository.Repository
+	authService *AuthService
+	opts        RegistrationOptions
+}
+
+// NewRegistrationService creates a new RegistrationService instance
+func NewRegistrationService(repo *repository.Repository, authService *AuthService, opts RegistrationOptions) *RegistrationService {
+	return &RegistrationService{
+		repo:        repo,
+		authService: authService,
+		opts:        opts,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *RegistrationService) withContext(ctx context.Context) *RegistrationService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// Register creates an account according to the registration mode; the account stays
+// inactive until verified when verification is enabled
+func (s *RegistrationService) Register(ctx context.Context, req *models.RegisterRequest, clientIP string) (*models.User, error) {
+	if s.opts.Mode == RegistrationClosed {
+		return nil, ErrRegistrationClosed
+	}
+
+	if s.opts.Captcha != nil {
+		if err := s.opts.Captcha.Verify(ctx, req.CaptchaToken, clientIP); err != nil {
+			if !errors.Is(err, captcha.ErrFailed) {
+				slog.ErrorContext(ctx, "captcha verification error", "error", err)
+			}
+			return nil, captcha.ErrFailed
+		}
+	}
+
+	var invitation *models.Invitation
+	if req.InvitationCode != "" || s.opts.Mode == RegistrationInvite {
+		var err error
+		invitation, err = s.validInvitation(req.InvitationCode)
+		if err != nil {
+			return nil, err
+		}
+		if invitation.Email != "" && !strings.EqualFold(invitation.Email, req.Email) {
+			return nil, i18n.NewError("invitation_email_mismatch")
+		}
+	}
+
+	switch s.opts.Verification {
+	case models.VerificationEmail:
+		if req.Email == "" {
+			return nil, i18n.NewError("email_required")
+		}
+	case models.VerificationPhone:
+		if req.Phone == "" {
+			return nil, i18n.NewError("phone_required")
+		}
+	}
+
+	user, err := s.authService.newUser(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invited users join the invitation's tenant; everyone else joins the default organization
+	var invitationID uint
+	if invitation != nil {
+		invitationID = invitation.ID
+		user.Role = invitation.Role
+		user.OrganizationID = invitation.OrganizationID
+		user.BranchID = invitation.BranchID
+	}
+	if user.OrganizationID == nil {
+		org, err := s.repo.GetOrganizationByCode(models.DefaultOrganizationCode)
+		if err != nil {
+			return nil, i18n.NewError("failed_to_create_user")
+		}
+		user.OrganizationID = &org.ID
+	}
+	if s.opts.Verification != "" {
+		user.IsActive = false
+		user.PendingVerification = s.opts.Verification
+	}
+
+	if err := s.repo.RegisterUser(user, invitationID); err != nil {
+		if errors.Is(err, repository.ErrInvitationUnavailable) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, i18n.NewError("failed_to_create_user")
+	}
+
+	if user.PendingVerification != "" {
+		if err := s.sendVerificationCode(user); err != nil {
+			slog.ErrorContext(ctx, "failed to send verification code", "user_id", user.ID, "error", err)
+		}
+	}
+
+	return user, nil
+}
+
+// VerifyAccount activates an account with the code sent at registration
+func (s *RegistrationService) VerifyAccount(req *models.VerifyAccountRequest) error {
+	invalid := i18n.NewError("invalid_verification_code")
+
+	user, err := s.repo.GetUserByUsername(req.Username)
+	if err != nil || user.PendingVerification == "" {
+		return invalid
+	}
+
+	code, err := s.repo.GetVerificationCode(user.ID)
+	if err != nil || time.Now().After(code.ExpiresAt) {
+		return invalid
+	}
+
+	// Every guess takes an attempt before it is compared
+	ok, err := s.repo.ConsumeVerificationAttempt(code.ID, maxVerificationAttempts)
+	if err != nil {
+		slog.Error("failed to count verification attempt", "user_id", user.ID, "error", err)
+		return i18n.NewError("failed_to_verify_account")
+	}
+	if !ok {
+		return invalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashResetToken(strings.TrimSpace(req.Code))), []byte(code.CodeHash)) != 1 {
+		return invalid
+	}
+
+	if err := s.repo.ActivateVerifiedUser(user.ID); err != nil {
+		return i18n.NewError("failed_to_verify_account")
+	}
+	return nil
+}
+
+// ResendVerification sends a new code; it always succeeds so it can't be used to
+// find out which accounts exist
+func (s *RegistrationService) ResendVerification(req *models.ResendVerificationRequest) error {
+	user, err := s.repo.GetUserByUsername(req.Username)
+	if err != nil || user.PendingVerification == "" {
+		return nil
+	}
+
+	// A new code inherits the attempts of an unexpired one, so there's no point sending it
+	// before the exhausted code expires
+	if code, err := s.repo.GetVerificationCode(user.ID); err == nil {
+		if time.Since(code.CreatedAt) < resendInterval {
+			return nil
+		}
+		if code.Attempts >= maxVerificationAttempts && time.Now().Before(code.ExpiresAt) {
+			return nil
+		}
+	}
+
+	if err := s.sendVerificationCode(user); err != nil {
+		slog.Error("failed to resend verification code", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// sendVerificationCode stores a fresh code and delivers it in the background
+func (s *RegistrationService) sendVerificationCode(user *models.User) error {
+	code, err := generateVerificationCode()
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.ReplaceVerificationCode(&models.VerificationCode{
+		UserID:    user.ID,
+		Channel:   user.PendingVerification,
+		CodeHash:  hashResetToken(code),
+		ExpiresAt: time.Now().Add(s.opts.VerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	notifier, to := s.opts.EmailNotifier, user.Email
+	if user.PendingVerification == models.VerificationPhone {
+		notifier, to = s.opts.SMSNotifier, user.Phone
+	}
+	if notifier == nil {
+		return fmt.Errorf("no notifier configured for %s verification", user.PendingVerification)
+	}
+	msg := notify.Message{
+		To:      to,
+		Subject: "Verify your Tiger FastTrack Card account",
+		Body:    fmt.Sprintf("Your Tiger FastTrack Card verification code is %s. It expires in %s.", code, s.opts.VerificationTTL),
+	}
+
+	userID := user.ID
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := notifier.Send(ctx, msg); err != nil {
+			slog.Error("failed to deliver verification code", "user_id", userID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// CreateInvitation issues a single-use invitation code (admin only)
+// When an email is given the code is also sent there. The new user joins the admin's
+// tenant unless another organization or branch is given
+func (s *RegistrationService) CreateInvitation(adminID uint, req *models.CreateInvitationRequest) (*models.InvitationResponse, error) {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return nil, err
+	}
+	organizationID, branchID, err := placement(s.repo, admin, req.OrganizationID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = "user"
+	}
+	if !containsString(models.InvitableRoles, role) {
+		return nil, i18n.NewError("invalid_invitation_role", strings.Join(models.InvitableRoles, ", "))
+	}
+
+	ttl := s.opts.InvitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	code, err := randomHex(12)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_generate_invitation")
+	}
+	code = "inv_" + code
+
+	invitation := &models.Invitation{
+		CodeHash:    hashResetToken(code),
+		Role:        role,
+		Email:       req.Email,
+		ExpiresAt:   time.Now().Add(ttl),
+		CreatedByID: adminID,
+
+		OrganizationID: &organizationID,
+		BranchID:       branchID,
+	}
+	if err := s.repo.CreateInvitation(invitation); err != nil {
+		return nil, i18n.NewError("failed_to_create_invitation")
+	}
+
+	if invitation.Email != "" && s.opts.EmailNotifier != nil {
+		msg := notify.Message{
+			To:      invitation.Email,
+			Subject: "You're invited to Tiger FastTrack Card",
+			Body: fmt.Sprintf("You have been invited to create a Tiger FastTrack Card account.\n\n"+
+				"Register with this email address and the invitation code below before %s.\n\n%s\n",
+				invitation.ExpiresAt.Format(time.RFC1123), code),
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := s.opts.EmailNotifier.Send(ctx, msg); err != nil {
+				slog.Error("failed to send invitation", "invitation_id", invitation.ID, "error", err)
+			}
+		}()
+	}
+
+	return &models.InvitationResponse{Invitation: *invitation, Code: code}, nil
+}
+
+// ListInvitations returns the invitations into the admin's tenant (admin only)
+func (s *RegistrationService) ListInvitations(adminID uint) ([]models.Invitation, error) {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForTenant(admin.Tenant()).GetAllInvitations()
+}
+
+// RevokeInvitation invalidates an unused invitation (admin only)
+func (s *RegistrationService) RevokeInvitation(adminID uint, id uint) error {
+	admin, err := s.authService.requireAdmin(adminID)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.repo.ForTenant(admin.Tenant()).GetInvitationByID(id)
+	if err != nil {
+		return err
+	}
+	if invitation.UsedAt != nil {
+		return i18n.NewError("invitation_used")
+	}
+
+	if err := s.repo.RevokeInvitation(id); err != nil {
+		return i18n.NewError("failed_to_revoke_invitation")
+	}
+	return nil
+}
+
+// validInvitation looks up an unused, unrevoked and unexpired invitation by code
+func (s *RegistrationService) validInvitation(code string) (*models.Invitation, error) {
+	if code == "" {
+		return nil, i18n.NewError("invitation_code_required")
+	}
+	invitation, err := s.repo.GetInvitationByHash(hashResetToken(strings.TrimSpace(code)))
+	if err != nil || invitation.UsedAt != nil || invitation.RevokedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+	return invitation, nil
+}
+
+// generateVerificationCode returns a random 6-digit code
+func generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -1,10 +1,16 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/lockout"
 	"tiger-fasttrack-card/internal/repository"
 	"tiger-fasttrack-card/internal/utils"
 	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/notify"
+	"tiger-fasttrack-card/internal/oidc"
+	"tiger-fasttrack-card/internal/tracing"
+	"time"
 )
 
 // Service is the main service that coordinates all sub-services
@@ -12,105 +18,546 @@ type Service struct {
 	AuthService      *AuthService
 	CardService      *CardService
 	CardOwnerService *CardOwnerService
+	PasswordReset    *PasswordResetService
+	TwoFactor        *TwoFactorService
+	APIClients       *APIClientService
+	OIDC             *OIDCService
+	Registration     *RegistrationService
+	People           *PersonService
+	Stock            *StockService
+	Organizations    *OrganizationService
+	Admin            *AdminService
+}
+
+// PasswordResetOptions configures the password reset flow
+type PasswordResetOptions struct {
+	Notifier notify.Notifier
+	TokenTTL time.Duration
+	ResetURL string
 }
 
 // New creates a new Service instance with all sub-services
-func New(repo *repository.Repository, jwtSecret string) *Service {
-	jwtManager := utils.NewJWTManager(jwtSecret)
-	
+func New(repo *repository.Repository, jwtManager *utils.JWTManager, loginGuard *lockout.Guard, passwords *utils.PasswordPolicy, reset PasswordResetOptions, twoFactor TwoFactorOptions, clientTokenTTL time.Duration, sso OIDCOptions, registration RegistrationOptions) *Service {
 	// Create auth service first as other services depend on it
-	authService := NewAuthService(repo, jwtManager)
+	authService := NewAuthService(repo, jwtManager, loginGuard, passwords, twoFactor, sso.LocalLoginDisabledRoles)
 	
 	// Create other services with auth service dependency
 	cardService := NewCardService(repo, authService)
 	cardOwnerService := NewCardOwnerService(repo, authService)
+	passwordResetService := NewPasswordResetService(repo, authService, reset.Notifier, reset.TokenTTL, reset.ResetURL)
+	twoFactorService := NewTwoFactorService(repo, authService)
+	apiClientService := NewAPIClientService(repo, authService, clientTokenTTL)
+	oidcService := NewOIDCService(repo, authService, sso)
+	registrationService := NewRegistrationService(repo, authService, registration)
+	personService := NewPersonService(repo, authService)
+	stockService := NewStockService(repo, authService)
+	organizationService := NewOrganizationService(repo, authService)
+	adminService := NewAdminService(repo, authService)
 
 	return &Service{
 		AuthService:      authService,
 		CardService:      cardService,
 		CardOwnerService: cardOwnerService,
+		PasswordReset:    passwordResetService,
+		TwoFactor:        twoFactorService,
+		APIClients:       apiClientService,
+		OIDC:             oidcService,
+		Registration:     registrationService,
+		People:           personService,
+		Stock:            stockService,
+		Organizations:    organizationService,
+		Admin:            adminService,
 	}
 }
 
 // Authentication service delegation methods
-func (s *Service) Register(req *models.RegisterRequest) (*models.User, error) {
-	return s.AuthService.Register(req)
+func (s *Service) Register(ctx context.Context, req *models.RegisterRequest, clientIP string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "service.Register")
+	defer tracing.End(span, &err)
+
+	return s.Registration.withContext(ctx).Register(ctx, req, clientIP)
+}
+
+func (s *Service) VerifyAccount(ctx context.Context, req *models.VerifyAccountRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "service.VerifyAccount")
+	defer tracing.End(span, &err)
+
+	return s.Registration.withContext(ctx).VerifyAccount(req)
+}
+
+func (s *Service) ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "service.ResendVerification")
+	defer tracing.End(span, &err)
+
+	return s.Registration.withContext(ctx).ResendVerification(req)
+}
+
+func (s *Service) CreateInvitation(ctx context.Context, adminID uint, req *models.CreateInvitationRequest) (_ *models.InvitationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateInvitation")
+	defer tracing.End(span, &err)
+
+	return s.Registration.withContext(ctx).CreateInvitation(adminID, req)
+}
+
+func (s *Service) ListInvitations(ctx context.Context, adminID uint) (_ []models.Invitation, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListInvitations")
+	defer tracing.End(span, &err)
+
+	return s.Registration.withContext(ctx).ListInvitations(adminID)
+}
+
+func (s *Service) RevokeInvitation(ctx context.Context, adminID uint, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "service.RevokeInvitation")
+	defer tracing.End(span, &err)
+
+	return s.Registration.withContext(ctx).RevokeInvitation(adminID, id)
+}
+
+func (s *Service) Login(ctx context.Context, req *models.LoginRequest, clientIP string) (_ *models.LoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.Login")
+	defer tracing.End(span, &err)
+
+	return s.AuthService.withContext(ctx).Login(req, clientIP)
+}
+
+func (s *Service) UnlockAccount(ctx context.Context, adminID uint, targetUserID uint) (err error) {
+	ctx, span := tracing.Start(ctx, "service.UnlockAccount")
+	defer tracing.End(span, &err)
+
+	return s.AuthService.withContext(ctx).UnlockAccount(adminID, targetUserID)
+}
+
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "service.RefreshToken")
+	defer tracing.End(span, &err)
+
+	return s.AuthService.withContext(ctx).RefreshToken(refreshToken)
+}
+
+func (s *Service) RequestPasswordReset(ctx context.Context, req *models.ForgotPasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "service.RequestPasswordReset")
+	defer tracing.End(span, &err)
+
+	return s.PasswordReset.withContext(ctx).RequestPasswordReset(req)
+}
+
+func (s *Service) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "service.ResetPassword")
+	defer tracing.End(span, &err)
+
+	return s.PasswordReset.withContext(ctx).ResetPassword(req)
+}
+
+// Two-factor service delegation methods
+func (s *Service) BeginTwoFactorSetup(ctx context.Context, userID uint) (_ *models.TwoFactorSetupResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.BeginTwoFactorSetup")
+	defer tracing.End(span, &err)
+
+	return s.TwoFactor.withContext(ctx).BeginSetup(userID)
+}
+
+func (s *Service) EnableTwoFactor(ctx context.Context, userID uint, code string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "service.EnableTwoFactor")
+	defer tracing.End(span, &err)
+
+	return s.TwoFactor.withContext(ctx).EnableTOTP(userID, code)
+}
+
+func (s *Service) DisableTwoFactor(ctx context.Context, userID uint, req *models.DisableTwoFactorRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "service.DisableTwoFactor")
+	defer tracing.End(span, &err)
+
+	return s.TwoFactor.withContext(ctx).DisableTOTP(userID, req)
 }
 
-func (s *Service) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
-	return s.AuthService.Login(req)
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "service.RegenerateRecoveryCodes")
+	defer tracing.End(span, &err)
+
+	return s.TwoFactor.withContext(ctx).RegenerateRecoveryCodes(userID, code)
+}
+
+func (s *Service) VerifyTwoFactorLogin(ctx context.Context, req *models.TwoFactorVerifyRequest, clientIP string) (_ *models.LoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.VerifyTwoFactorLogin")
+	defer tracing.End(span, &err)
+
+	return s.TwoFactor.withContext(ctx).VerifyLogin(req, clientIP)
+}
+
+func (s *Service) BeginTwoFactorSetupForChallenge(ctx context.Context, challengeToken string) (_ *models.TwoFactorSetupResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.BeginTwoFactorSetupForChallenge")
+	defer tracing.End(span, &err)
+
+	user, err := s.TwoFactor.withContext(ctx).ResolveChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.TwoFactor.withContext(ctx).BeginSetup(user.ID)
+}
+
+func (s *Service) EnrollTwoFactorDuringLogin(ctx context.Context, req *models.TwoFactorVerifyRequest) (_ *models.TwoFactorEnableResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.EnrollTwoFactorDuringLogin")
+	defer tracing.End(span, &err)
+
+	return s.TwoFactor.withContext(ctx).EnrollDuringLogin(req)
 }
 
-func (s *Service) RefreshToken(refreshToken string) (string, error) {
-	return s.AuthService.RefreshToken(refreshToken)
+func (s *Service) JWKS() utils.JWKSet {
+	return s.AuthService.JWKS()
 }
 
-func (s *Service) GetUserProfile(userID uint) (*models.User, error) {
-	return s.AuthService.GetUserProfile(userID)
+// Single sign-on delegation methods
+func (s *Service) BeginOIDCLogin() (*oidc.Flow, string, error) {
+	return s.OIDC.BeginLogin()
 }
 
-func (s *Service) UpdateUserProfile(userID uint, req *models.UpdateProfileRequest) (*models.User, error) {
-	return s.AuthService.UpdateUserProfile(userID, req)
+func (s *Service) CompleteOIDCLogin(ctx context.Context, code string, flow *oidc.Flow) (_ *models.LoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.CompleteOIDCLogin")
+	defer tracing.End(span, &err)
+
+	return s.OIDC.withContext(ctx).CompleteLogin(ctx, code, flow)
 }
 
-func (s *Service) ChangePassword(userID uint, req *models.ChangePasswordRequest) error {
-	return s.AuthService.ChangePassword(userID, req)
+// API client service delegation methods
+func (s *Service) CreateAPIClient(ctx context.Context, adminID uint, req *models.CreateAPIClientRequest) (_ *models.APIClientCredentials, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateAPIClient")
+	defer tracing.End(span, &err)
+
+	return s.APIClients.withContext(ctx).CreateClient(adminID, req)
+}
+
+func (s *Service) ListAPIClients(ctx context.Context, adminID uint) (_ []models.APIClient, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListAPIClients")
+	defer tracing.End(span, &err)
+
+	return s.APIClients.withContext(ctx).ListClients(adminID)
+}
+
+func (s *Service) RotateAPIClientSecret(ctx context.Context, adminID uint, id uint) (_ *models.APIClientCredentials, err error) {
+	ctx, span := tracing.Start(ctx, "service.RotateAPIClientSecret")
+	defer tracing.End(span, &err)
+
+	return s.APIClients.withContext(ctx).RotateSecret(adminID, id)
+}
+
+func (s *Service) RevokeAPIClient(ctx context.Context, adminID uint, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "service.RevokeAPIClient")
+	defer tracing.End(span, &err)
+
+	return s.APIClients.withContext(ctx).RevokeClient(adminID, id)
+}
+
+func (s *Service) IssueClientToken(ctx context.Context, req *models.ClientTokenRequest, clientIP string) (_ *models.ClientTokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "service.IssueClientToken")
+	defer tracing.End(span, &err)
+
+	return s.APIClients.withContext(ctx).IssueToken(req, clientIP)
+}
+
+func (s *Service) GetUserProfile(ctx context.Context, userID uint) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetUserProfile")
+	defer tracing.End(span, &err)
+
+	return s.AuthService.withContext(ctx).GetUserProfile(userID)
+}
+
+func (s *Service) UpdateUserProfile(ctx context.Context, userID uint, req *models.UpdateProfileRequest) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateUserProfile")
+	defer tracing.End(span, &err)
+
+	return s.AuthService.withContext(ctx).UpdateUserProfile(userID, req)
+}
+
+func (s *Service) ChangePassword(ctx context.Context, userID uint, req *models.ChangePasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "service.ChangePassword")
+	defer tracing.End(span, &err)
+
+	return s.AuthService.withContext(ctx).ChangePassword(userID, req)
 }
 
 // Card service delegation methods
-func (s *Service) GetAllCards(userID uint) ([]models.Card, error) {
-	return s.CardService.GetAllCards(userID)
+func (s *Service) GetAllCards(ctx context.Context, userID uint, filter models.CardFilter) (_ []models.Card, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetAllCards")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).GetAllCards(userID, filter)
+}
+
+func (s *Service) GetCardByID(ctx context.Context, userID uint, id uint) (_ *models.Card, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetCardByID")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).GetCardByID(userID, id)
+}
+
+func (s *Service) CreateCard(ctx context.Context, userID uint, req *models.CreateCardRequest) (_ *models.Card, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateCard")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).CreateCard(userID, req)
 }
 
-func (s *Service) GetCardByID(userID uint, id uint) (*models.Card, error) {
-	return s.CardService.GetCardByID(userID, id)
+func (s *Service) UpdateCard(ctx context.Context, userID uint, id uint, req *models.UpdateCardRequest) (_ *models.Card, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateCard")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).UpdateCard(userID, id, req)
+}
+
+func (s *Service) DeleteCard(ctx context.Context, userID uint, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "service.DeleteCard")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).DeleteCard(userID, id)
+}
+
+func (s *Service) CreateCardNumberRange(ctx context.Context, userID uint, cardID uint, req *models.CreateCardNumberRangeRequest) (_ *models.CardNumberRange, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateCardNumberRange")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).CreateNumberRange(userID, cardID, req)
+}
+
+func (s *Service) GetCardNumberRanges(ctx context.Context, userID uint, cardID uint) (_ []models.CardNumberRange, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetCardNumberRanges")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).ListNumberRanges(userID, cardID)
+}
+
+func (s *Service) GetCardNumberRange(ctx context.Context, userID uint, cardID uint, rangeID uint) (_ *models.CardNumberRange, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetCardNumberRange")
+	defer tracing.End(span, &err)
+
+	return s.CardService.withContext(ctx).GetNumberRange(userID, cardID, rangeID)
+}
+
+// Stock service delegation methods
+func (s *Service) CreateBranch(ctx context.Context, userID uint, req *models.CreateBranchRequest) (_ *models.Branch, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateBranch")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).CreateBranch(userID, req)
+}
+
+func (s *Service) ListBranches(ctx context.Context, userID uint) (_ []models.Branch, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListBranches")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).ListBranches(userID)
+}
+
+func (s *Service) UpdateBranch(ctx context.Context, userID uint, id uint, req *models.UpdateBranchRequest) (_ *models.Branch, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateBranch")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).UpdateBranch(userID, id, req)
+}
+
+func (s *Service) ReceiveCardBatch(ctx context.Context, userID uint, req *models.ReceiveBatchRequest) (_ *models.CardBatch, err error) {
+	ctx, span := tracing.Start(ctx, "service.ReceiveCardBatch")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).ReceiveBatch(userID, req)
+}
+
+func (s *Service) ListCardBatches(ctx context.Context, userID uint, cardID uint) (_ []models.CardBatch, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListCardBatches")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).ListBatches(userID, cardID)
+}
+
+func (s *Service) AllocateStock(ctx context.Context, userID uint, req *models.AllocateStockRequest) (_ []models.CardSerial, err error) {
+	ctx, span := tracing.Start(ctx, "service.AllocateStock")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).Allocate(userID, req)
+}
+
+func (s *Service) ListCardSerials(ctx context.Context, userID uint, filter models.StockFilter, limit int) (_ []models.CardSerial, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListCardSerials")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).ListSerials(userID, filter, limit)
+}
+
+func (s *Service) GetCardSerial(ctx context.Context, userID uint, cardID uint, serial string) (_ *models.CardSerial, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetCardSerial")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).GetSerial(userID, cardID, serial)
+}
+
+func (s *Service) UpdateCardSerialState(ctx context.Context, userID uint, cardID uint, serial string, req *models.UpdateSerialStateRequest) (_ *models.CardSerial, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateCardSerialState")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).UpdateSerialState(userID, cardID, serial, req)
+}
+
+func (s *Service) StockSummary(ctx context.Context, userID uint, filter models.StockFilter) (_ []models.StockLevel, err error) {
+	ctx, span := tracing.Start(ctx, "service.StockSummary")
+	defer tracing.End(span, &err)
+
+	return s.Stock.withContext(ctx).Summary(userID, filter)
 }
 
-func (s *Service) CreateCard(userID uint, req *models.CreateCardRequest) (*models.Card, error) {
-	return s.CardService.CreateCard(userID, req)
+// Organization service delegation methods
+func (s *Service) CreateOrganization(ctx context.Context, userID uint, req *models.CreateOrganizationRequest) (_ *models.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateOrganization")
+	defer tracing.End(span, &err)
+
+	return s.Organizations.withContext(ctx).CreateOrganization(userID, req)
+}
+
+func (s *Service) ListOrganizations(ctx context.Context, userID uint) (_ []models.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListOrganizations")
+	defer tracing.End(span, &err)
+
+	return s.Organizations.withContext(ctx).ListOrganizations(userID)
+}
+
+func (s *Service) UpdateOrganization(ctx context.Context, userID uint, id uint, req *models.UpdateOrganizationRequest) (_ *models.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateOrganization")
+	defer tracing.End(span, &err)
+
+	return s.Organizations.withContext(ctx).UpdateOrganization(userID, id, req)
+}
+
+func (s *Service) AssignUserTenant(ctx context.Context, adminID uint, targetUserID uint, req *models.AssignTenantRequest) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "service.AssignUserTenant")
+	defer tracing.End(span, &err)
+
+	return s.Organizations.withContext(ctx).AssignUserTenant(adminID, targetUserID, req)
+}
+
+// Person service delegation methods
+func (s *Service) GetPerson(ctx context.Context, userID uint, personID uint) (_ *models.Person, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPerson")
+	defer tracing.End(span, &err)
+
+	return s.People.withContext(ctx).GetPerson(userID, personID)
 }
 
-func (s *Service) UpdateCard(userID uint, id uint, req *models.UpdateCardRequest) (*models.Card, error) {
-	return s.CardService.UpdateCard(userID, id, req)
+func (s *Service) GetPersonByDocument(ctx context.Context, userID uint, doc models.IdentityDocument) (_ *models.Person, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPersonByDocument")
+	defer tracing.End(span, &err)
+
+	return s.People.withContext(ctx).GetPersonByDocument(userID, doc)
 }
 
-func (s *Service) DeleteCard(userID uint, id uint) error {
-	return s.CardService.DeleteCard(userID, id)
+func (s *Service) ListPeople(ctx context.Context, userID uint) (_ []models.Person, err error) {
+	ctx, span := tracing.Start(ctx, "service.ListPeople")
+	defer tracing.End(span, &err)
+
+	return s.People.withContext(ctx).ListPeople(userID)
+}
+
+func (s *Service) UpdatePerson(ctx context.Context, userID uint, personID uint, req *models.UpdatePersonRequest) (_ *models.Person, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdatePerson")
+	defer tracing.End(span, &err)
+
+	return s.People.withContext(ctx).UpdatePerson(userID, personID, req)
 }
 
 // CardOwner service delegation methods
-func (s *Service) RegisterCardOwner(userID uint, req *models.RegisterOwnerRequest) (*models.CardOwner, error) {
-	return s.CardOwnerService.RegisterCardOwner(userID, req)
+func (s *Service) RegisterCardOwner(ctx context.Context, userID uint, req *models.RegisterOwnerRequest) (_ *models.CardOwner, err error) {
+	ctx, span := tracing.Start(ctx, "service.RegisterCardOwner")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).RegisterCardOwner(userID, req)
 }
 
-func (s *Service) RegisterMultipleCards(userID uint, req *models.RegisterMultipleCardsRequest) ([]models.CardOwner, error) {
-	return s.CardOwnerService.RegisterMultipleCards(userID, req)
+func (s *Service) RegisterMultipleCards(ctx context.Context, userID uint, req *models.RegisterMultipleCardsRequest) (_ []models.CardOwner, err error) {
+	ctx, span := tracing.Start(ctx, "service.RegisterMultipleCards")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).RegisterMultipleCards(userID, req)
 }
 
-func (s *Service) GetCardOwnerProfile(userID uint) (*models.CardOwnerWithCard, error) {
+func (s *Service) GetCardOwnerProfile(ctx context.Context, userID uint) (_ *models.CardOwnerWithCard, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetCardOwnerProfile")
+	defer tracing.End(span, &err)
+
 	// For backward compatibility, get the first card owner registration
-	profiles, err := s.CardOwnerService.GetCardOwnerProfiles(userID)
+	profiles, err := s.CardOwnerService.withContext(ctx).GetCardOwnerProfiles(userID)
 	if err != nil {
 		return nil, err
 	}
 	if len(profiles) == 0 {
-		return nil, errors.New("card owner not found")
+		return nil, i18n.NewError("card_owner_not_found")
 	}
 	return &profiles[0], nil
 }
 
-func (s *Service) GetCardOwnerProfiles(userID uint) ([]models.CardOwnerWithCard, error) {
-	return s.CardOwnerService.GetCardOwnerProfiles(userID)
+func (s *Service) GetCardOwnerProfiles(ctx context.Context, userID uint) (_ []models.CardOwnerWithCard, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetCardOwnerProfiles")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).GetCardOwnerProfiles(userID)
 }
 
-func (s *Service) GetAllCardOwners(userID uint) ([]models.CardOwnerWithCard, error) {
-	return s.CardOwnerService.GetAllCardOwners(userID)
+func (s *Service) GetAllCardOwners(ctx context.Context, userID uint) (_ []models.CardOwnerWithCard, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetAllCardOwners")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).GetAllCardOwners(userID)
+}
+
+func (s *Service) UpdateCardOwner(ctx context.Context, userID uint, cardOwnerID uint, req *models.UpdateCardOwnerRequest) (_ *models.CardOwner, err error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateCardOwner")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).UpdateCardOwner(userID, cardOwnerID, req)
 }
 
-func (s *Service) UpdateCardOwner(userID uint, cardOwnerID uint, req *models.UpdateCardOwnerRequest) (*models.CardOwner, error) {
-	return s.CardOwnerService.UpdateCardOwner(userID, cardOwnerID, req)
+func (s *Service) DeleteCardOwner(ctx context.Context, userID uint, cardOwnerID uint) (err error) {
+	ctx, span := tracing.Start(ctx, "service.DeleteCardOwner")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).DeleteCardOwner(userID, cardOwnerID)
+}
+
+func (s *Service) ValidateDuplicateCardRegistration(ctx context.Context, cardID uint, cardNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "service.ValidateDuplicateCardRegistration")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).ValidateDuplicateCardRegistration(cardID, cardNumber)
+}
+
+func (s *Service) SearchCardOwnersByCardNameAndNumber(ctx context.Context, userID uint, cardName string, cardNumber string) (_ []models.CardOwnerWithCard, err error) {
+	ctx, span := tracing.Start(ctx, "service.SearchCardOwnersByCardNameAndNumber")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).SearchCardOwnersByCardNameAndNumber(userID, cardName, cardNumber)
 }
 
-func (s *Service) DeleteCardOwner(userID uint, cardOwnerID uint) error {
-	return s.CardOwnerService.DeleteCardOwner(userID, cardOwnerID)
+func (s *Service) SearchCardOwnersByIDCardOrPhone(ctx context.Context, userID uint, idCard string, phoneNumber string, documentType string, issuingCountry string) (_ []models.CardOwnerWithCard, err error) {
+	ctx, span := tracing.Start(ctx, "service.SearchCardOwnersByIDCardOrPhone")
+	defer tracing.End(span, &err)
+
+	return s.CardOwnerService.withContext(ctx).SearchCardOwnersByIDCardOrPhone(userID, idCard, phoneNumber, documentType, issuingCountry)
+}
+
+// Operator command delegation methods
+func (s *Service) CreateUser(ctx context.Context, req *models.CreateUserRequest) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "service.CreateUser")
+	defer tracing.End(span, &err)
+
+	return s.Admin.withContext(ctx).CreateUser(req)
+}
+
+func (s *Service) ResetUserPassword(ctx context.Context, username string, password string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "service.ResetUserPassword")
+	defer tracing.End(span, &err)
+
+	return s.Admin.withContext(ctx).ResetPassword(username, password)
+}
+
+func (s *Service) ImportCards(ctx context.Context, cards []models.CreateCardRequest) (_ *models.CardImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "service.ImportCards")
+	defer tracing.End(span, &err)
+
+	return s.Admin.withContext(ctx).ImportCards(cards)
 }
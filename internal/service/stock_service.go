@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+)
+
+// Serial list limits
+const (
+	defaultSerialLimit = 100
+	maxSerialLimit     = 1000
+)
+
+// StockService manages physical card stock: branches, received batches and the state of
+// each serial. Every change goes through the stock ledger, which also keeps the card's
+// quantity up to date. Admins only see the stock of their organization or branch
+type StockService struct {
+	repo        *repository.Repository
+	authService *AuthService
+}
+
+// NewStockService creates a new StockService instance
+func NewStockService(repo *repository.Repository, authService *AuthService) *StockService {
+	return &StockService{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *StockService) withContext(ctx context.Context) *StockService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// CreateBranch adds a branch to the admin's organization; super admins may name another
+// organization (organization-wide admin only)
+func (s *StockService) CreateBranch(userID uint, req *models.CreateBranchRequest) (*models.Branch, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.BranchID != nil {
+		return nil, ErrInsufficientPermissions
+	}
+	organizationID, _, err := placement(s.repo, user, req.OrganizationID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if existing, _ := s.repo.GetBranchByCode(code); existing != nil {
+		return nil, i18n.NewError("branch_code_exists", code)
+	}
+
+	branch := &models.Branch{
+		OrganizationID: organizationID,
+		Code:           code,
+		Name:           strings.TrimSpace(req.Name),
+		IsActive:       true,
+	}
+	if err := s.repo.CreateBranch(branch); err != nil {
+		return nil, i18n.NewError("failed_to_create_branch")
+	}
+	return branch, nil
+}
+
+// ListBranches returns the branches in the admin's tenant (admin only)
+func (s *StockService) ListBranches(userID uint) ([]models.Branch, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForTenant(user.Tenant()).GetBranches()
+}
+
+// UpdateBranch renames or (de)activates a branch (organization-wide admin only)
+func (s *StockService) UpdateBranch(userID uint, id uint, req *models.UpdateBranchRequest) (*models.Branch, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.BranchID != nil {
+		return nil, ErrInsufficientPermissions
+	}
+
+	branch, err := s.repo.ForTenant(user.Tenant()).GetBranchByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		branch.Name = name
+	}
+	if req.IsActive != nil {
+		branch.IsActive = *req.IsActive
+	}
+	if err := s.repo.UpdateBranch(branch); err != nil {
+		return nil, i18n.NewError("failed_to_update_branch")
+	}
+	return branch, nil
+}
+
+// ReceiveBatch records a delivery of physical cards; every serial starts in stock (admin only)
+// Serials come from first_serial..last_serial or from a pre-generated card number range.
+// The batch belongs to the given branch's organization, or is held centrally by the admin's
+// organization (branch admins always receive into their branch)
+func (s *StockService) ReceiveBatch(userID uint, req *models.ReceiveBatchRequest) (*models.CardBatch, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetCardByID(req.CardID); err != nil {
+		return nil, err
+	}
+	organizationID, branchID, err := placement(s.repo, user, req.OrganizationID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var serials []string
+	switch {
+	case req.NumberRangeID != nil:
+		var rng *models.CardNumberRange
+		rng, err = s.repo.GetCardNumberRange(req.CardID, *req.NumberRangeID)
+		if err != nil {
+			return nil, err
+		}
+		serials, err = rangeNumbers(rng)
+		if err != nil {
+			return nil, err
+		}
+	case req.FirstSerial != "" && req.LastSerial != "":
+		serials, err = expandSerials(req.FirstSerial, req.LastSerial)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, i18n.NewError("batch_serials_required")
+	}
+
+	batch := &models.CardBatch{
+		OrganizationID: organizationID,
+		CardID:         req.CardID,
+		NumberRangeID:  req.NumberRangeID,
+		FirstSerial:    serials[0],
+		LastSerial:     serials[len(serials)-1],
+		Quantity:       len(serials),
+		BranchID:       branchID,
+		Supplier:       strings.TrimSpace(req.Supplier),
+		Note:           req.Note,
+		ReceivedByID:   userID,
+	}
+	if err := s.repo.ReceiveCardBatch(batch, serials); err != nil {
+		return nil, stockError(err, "failed to receive card batch")
+	}
+	return batch, nil
+}
+
+// ListBatches returns the received batches of a card, or of all cards when cardID is 0 (admin only)
+func (s *StockService) ListBatches(userID uint, cardID uint) ([]models.CardBatch, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForTenant(user.Tenant()).GetCardBatches(cardID)
+}
+
+// Allocate moves in-stock serials to a branch of the same organization (admin only)
+func (s *StockService) Allocate(userID uint, req *models.AllocateStockRequest) ([]models.CardSerial, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	repo := s.repo.ForTenant(user.Tenant())
+
+	branch, err := repo.GetBranchByID(req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	if !branch.IsActive {
+		return nil, i18n.NewError("branch_not_active")
+	}
+
+	var serials []string
+	if req.FirstSerial != "" && req.LastSerial != "" {
+		serials, err = expandSerials(req.FirstSerial, req.LastSerial)
+	} else {
+		serials, err = uniqueSerials(req.Serials)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	move := models.StockMovement{ToBranchID: &branch.ID, Note: req.Note, UserID: userID}
+	moved, err := repo.MoveCardSerials(req.CardID, serials, move, func(serial *models.CardSerial) error {
+		if serial.State != models.SerialInStock {
+			return i18n.NewError("serial_not_in_stock", serial.Serial)
+		}
+		if serial.OrganizationID != branch.OrganizationID {
+			return i18n.NewError("serial_other_organization", serial.Serial)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, stockError(err, "failed to allocate stock")
+	}
+	return moved, nil
+}
+
+// ListSerials returns up to limit serials of a card matching filter (admin only)
+func (s *StockService) ListSerials(userID uint, filter models.StockFilter, limit int) ([]models.CardSerial, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	if filter.State != "" {
+		if _, ok := models.SerialTransitions[filter.State]; !ok {
+			return nil, i18n.NewError("invalid_serial_state")
+		}
+	}
+	if limit <= 0 {
+		limit = defaultSerialLimit
+	}
+	if limit > maxSerialLimit {
+		limit = maxSerialLimit
+	}
+	return s.repo.ForTenant(user.Tenant()).GetCardSerials(filter, limit)
+}
+
+// GetSerial returns a serial with its ledger history (admin only)
+func (s *StockService) GetSerial(userID uint, cardID uint, serial string) (*models.CardSerial, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.repo.ForTenant(user.Tenant()).GetCardSerial(cardID, models.NormalizeSerial(serial))
+	if err != nil {
+		return nil, err
+	}
+	row.Movements, err = s.repo.GetStockMovements(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// UpdateSerialState moves a serial along SerialTransitions (admin only)
+func (s *StockService) UpdateSerialState(userID uint, cardID uint, serial string, req *models.UpdateSerialStateRequest) (*models.CardSerial, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	repo := s.repo.ForTenant(user.Tenant())
+
+	state := strings.ToLower(strings.TrimSpace(req.State))
+	if _, ok := models.SerialTransitions[state]; !ok {
+		return nil, i18n.NewError("invalid_serial_state")
+	}
+	if req.CardOwnerID != nil {
+		if state != models.SerialIssued {
+			return nil, i18n.NewError("card_owner_not_issuing")
+		}
+		owner, err := repo.GetCardOwnerByID(*req.CardOwnerID)
+		if err != nil {
+			return nil, err
+		}
+		if owner.CardID != cardID {
+			return nil, i18n.NewError("card_owner_other_card")
+		}
+	}
+
+	move := models.StockMovement{ToState: state, CardOwnerID: req.CardOwnerID, Note: req.Note, UserID: userID}
+	moved, err := repo.MoveCardSerials(cardID, []string{models.NormalizeSerial(serial)}, move, func(row *models.CardSerial) error {
+		if !canTransition(row.State, state) {
+			return i18n.NewError("invalid_serial_transition", row.Serial, row.State, state)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, stockError(err, "failed to update serial")
+	}
+	return &moved[0], nil
+}
+
+// Summary counts serials per card, branch and state (admin only)
+func (s *StockService) Summary(userID uint, filter models.StockFilter) ([]models.StockLevel, error) {
+	user, err := s.authService.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForTenant(user.Tenant()).GetStockLevels(filter)
+}
+
+// stockError passes validation errors from a stock movement through and hides database errors
+func stockError(err error, fallback string) error {
+	var localized *i18n.Error
+	if errors.As(err, &localized) {
+		return err
+	}
+	return errors.New(fallback)
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range models.SerialTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// expandSerials lists the serials from first to last; both must share a prefix and end in a
+// zero-padded number of the same width, e.g. TFC-000001 to TFC-000500
+func expandSerials(first, last string) ([]string, error) {
+	first, last = models.NormalizeSerial(first), models.NormalizeSerial(last)
+	prefix, firstDigits := splitSerial(first)
+	lastPrefix, lastDigits := splitSerial(last)
+	if firstDigits == "" || prefix != lastPrefix || len(firstDigits) != len(lastDigits) || len(firstDigits) > 18 {
+		return nil, i18n.NewError("invalid_serial_range")
+	}
+
+	from, _ := strconv.ParseInt(firstDigits, 10, 64)
+	to, _ := strconv.ParseInt(lastDigits, 10, 64)
+	if to < from || to-from >= models.MaxBatchSize {
+		return nil, i18n.NewError("invalid_serial_count", models.MaxBatchSize)
+	}
+
+	serials := make([]string, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		digits := strconv.FormatInt(n, 10)
+		serials = append(serials, prefix+strings.Repeat("0", len(firstDigits)-len(digits))+digits)
+	}
+	return serials, nil
+}
+
+// splitSerial splits a serial into its prefix and trailing digits
+func splitSerial(serial string) (string, string) {
+	i := len(serial)
+	for i > 0 && serial[i-1] >= '0' && serial[i-1] <= '9' {
+		i--
+	}
+	return serial[:i], serial[i:]
+}
+
+// uniqueSerials normalizes a serial list, dropping blanks and duplicates
+func uniqueSerials(list []string) ([]string, error) {
+	seen := make(map[string]bool, len(list))
+	serials := make([]string, 0, len(list))
+	for _, serial := range list {
+		serial = models.NormalizeSerial(serial)
+		if serial == "" || seen[serial] {
+			continue
+		}
+		seen[serial] = true
+		serials = append(serials, serial)
+	}
+	if len(serials) == 0 || len(serials) > models.MaxBatchSize {
+		return nil, i18n.NewError("invalid_serial_count", models.MaxBatchSize)
+	}
+	return serials, nil
+}
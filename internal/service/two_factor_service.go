@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"tiger-fasttrack-card/internal/lockout"
+	"tiger-fasttrack-card/internal/metrics"
+	"tiger-fasttrack-card/internal/models"
+	"tiger-fasttrack-card/internal/repository"
+	"tiger-fasttrack-card/internal/utils"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const recoveryCodeCount = 10
+
+// TwoFactorService handles TOTP enrollment and the second step of login
+type TwoFactorService struct {
+	repo        *repository.Repository
+	authService *AuthService
+}
+
+// NewTwoFactorService creates a new TwoFactorService instance
+func NewTwoFactorService(repo *repository.Repository, authService *AuthService) *TwoFactorService {
+	return &TwoFactorService{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// withContext returns a copy whose queries run with ctx
+func (s *TwoFactorService) withContext(ctx context.Context) *TwoFactorService {
+	clone := *s
+	clone.repo = s.repo.WithContext(ctx)
+	clone.authService = s.authService.withContext(ctx)
+	return &clone
+}
+
+// BeginSetup generates a new pending TOTP secret and returns provisioning details
+// The secret only takes effect once confirmed with EnableTOTP
+func (s *TwoFactorService) BeginSetup(userID uint) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, i18n.NewError("two_factor_already_enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, i18n.NewError("failed_to_generate_secret")
+	}
+
+	user.TOTPPending = secret
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, i18n.NewError("failed_to_start_two_factor")
+	}
+
+	uri := utils.TOTPProvisioningURI(s.authService.twoFactor.Issuer, user.Username, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, i18n.NewError("failed_to_generate_qr")
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// EnableTOTP confirms the pending secret with a code and returns fresh recovery codes
+func (s *TwoFactorService) EnableTOTP(userID uint, code string) ([]string, error) {
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, i18n.NewError("two_factor_already_enabled")
+	}
+	if user.TOTPPending == "" {
+		return nil, i18n.NewError("two_factor_setup_not_started")
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPPending, code, time.Now())
+	if !ok {
+		return nil, i18n.NewError("invalid_two_factor_code")
+	}
+
+	codes, err := s.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = user.TOTPPending
+	user.TOTPPending = ""
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, i18n.NewError("failed_to_enable_two_factor")
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor login after re-checking the password and a code
+// Users whose role requires two-factor login can't disable it
+func (s *TwoFactorService) DisableTOTP(userID uint, req *models.DisableTwoFactorRequest) error {
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return i18n.NewError("two_factor_not_enabled")
+	}
+	if s.authService.requiresTwoFactor(user) {
+		return i18n.NewError("two_factor_required")
+	}
+	if !utils.CheckPassword(req.Password, user.Password) {
+		return i18n.NewError("password_incorrect")
+	}
+	if err := s.verifyCode(user, req.Code); err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPPending = ""
+	user.TOTPLastStep = 0
+	if err := s.repo.UpdateUser(user); err != nil {
+		return i18n.NewError("failed_to_disable_two_factor")
+	}
+
+	if err := s.repo.DeleteRecoveryCodes(user.ID); err != nil {
+		return i18n.NewError("failed_to_remove_recovery")
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after verifying a TOTP code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.authService.ValidateUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, i18n.NewError("two_factor_not_enabled")
+	}
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// ResolveChallenge returns the user a login challenge token was issued to
+func (s *TwoFactorService) ResolveChallenge(challengeToken string) (*models.User, error) {
+	claims, err := s.authService.jwtManager.ValidateToken(challengeToken)
+	if err != nil || claims.TokenType != utils.TokenTypeChallenge {
+		return nil, i18n.NewError("invalid_challenge_token")
+	}
+	return s.authService.ValidateUserAccess(claims.UserID)
+}
+
+// VerifyLogin completes a two-step login with a TOTP or recovery code
+// Wrong codes count towards the same lockout as wrong passwords
+func (s *TwoFactorService) VerifyLogin(req *models.TwoFactorVerifyRequest, clientIP string) (*models.LoginResponse, error) {
+	user, err := s.ResolveChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, i18n.NewError("two_factor_not_enabled")
+	}
+
+	if err := s.authService.loginGuard.Check(user.Username, clientIP); err != nil {
+		var locked *lockout.LockedError
+		if errors.As(err, &locked) {
+			metrics.LoginFailures.WithLabelValues(metrics.LoginFailureLockedOut).Inc()
+			return nil, err
+		}
+		return nil, i18n.NewError("failed_to_verify_login")
+	}
+
+	if err := s.verifyCode(user, req.Code); err != nil {
+		s.authService.recordLoginFailure(user.Username, clientIP, metrics.LoginFailureTwoFactor)
+		return nil, err
+	}
+
+	return s.authService.issueTokens(user)
+}
+
+// EnrollDuringLogin enables TOTP for a user whose role requires it, using the
+// login challenge instead of an access token, and completes the login
+func (s *TwoFactorService) EnrollDuringLogin(req *models.TwoFactorVerifyRequest) (*models.TwoFactorEnableResponse, error) {
+	user, err := s.ResolveChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.EnableTOTP(user.ID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload so the tokens reflect the enabled state
+	user, err = s.authService.ValidateUserAccess(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	login, err := s.authService.issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnableResponse{
+		RecoveryCodes: codes,
+		Login:         login,
+	}, nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code
+func (s *TwoFactorService) verifyCode(user *models.User, code string) error {
+	if err := s.verifyTOTP(user, code); err == nil {
+		return nil
+	}
+
+	used, err := s.repo.ConsumeRecoveryCode(user.ID, hashRecoveryCode(code))
+	if err != nil {
+		return i18n.NewError("failed_to_verify_recovery")
+	}
+	if !used {
+		return i18n.NewError("invalid_two_factor_code")
+	}
+	return nil
+}
+
+// verifyTOTP checks a TOTP code and rejects replays of an already used time step
+func (s *TwoFactorService) verifyTOTP(user *models.User, code string) error {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return i18n.NewError("invalid_two_factor_code")
+	}
+
+	user.TOTPLastStep = step
+	if err := s.repo.UpdateUser(user); err != nil {
+		return i18n.NewError("failed_to_verify_two_factor")
+	}
+	return nil
+}
+
+// replaceRecoveryCodes generates new recovery codes, storing only their hashes
+func (s *TwoFactorService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, i18n.NewError("failed_to_generate_recovery")
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, i18n.NewError("failed_to_store_recovery")
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code like "abcd-efgh" (40 bits of entropy)
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+// hashRecoveryCode normalizes a code (case, dashes, spaces) and hashes it
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
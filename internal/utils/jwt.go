@@ -2,33 +2,77 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token types carried in the "typ" claim
+// Tokens issued before types were introduced have an empty type
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeChallenge = "2fa_challenge" // Only accepted by the two-factor verification endpoints
+	TokenTypeClient    = "client"        // OAuth2 client-credentials token for partner systems
+)
+
+// RefreshTokenTTL is how long a refresh token stays valid; signing keys must keep verifying
+// for at least this long after they retire
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// KeyProvider supplies asymmetric keys for signing and verification
+type KeyProvider interface {
+	// SigningKey returns the key new tokens are signed with
+	SigningKey() (*SigningKey, error)
+	// VerificationKey returns any key that may still appear on unexpired tokens
+	VerificationKey(kid string) (*SigningKey, error)
+	// VerificationKeys returns every key that may still appear on unexpired tokens
+	VerificationKeys() []*SigningKey
+}
+
+// JWTManager issues and validates tokens
+// With a KeyProvider tokens are signed asymmetrically and carry a "kid" header;
+// the HS256 secret is then only used to verify tokens issued before the switch
 type JWTManager struct {
 	secretKey string
+	keys      KeyProvider
 }
 
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	TokenType    string `json:"typ,omitempty"`
+	TokenVersion int    `json:"ver,omitempty"`       // Refresh tokens only; must match User.TokenVersion
+	ClientID     string `json:"client_id,omitempty"` // Client tokens only
+	Scope        string `json:"scope,omitempty"`     // Client tokens only; space-separated
 	jwt.RegisteredClaims
 }
 
+// NewJWTManager creates a manager that signs and verifies with an HS256 secret
 func NewJWTManager(secretKey string) *JWTManager {
 	return &JWTManager{
 		secretKey: secretKey,
 	}
 }
 
+// NewJWTManagerWithKeys creates a manager that signs with the provider's current key
+// legacySecret may be empty to reject HS256 tokens entirely
+func NewJWTManagerWithKeys(keys KeyProvider, legacySecret string) *JWTManager {
+	return &JWTManager{
+		secretKey: legacySecret,
+		keys:      keys,
+	}
+}
+
 func (j *JWTManager) GenerateToken(userID uint, username, role string) (string, error) {
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -36,30 +80,89 @@ func (j *JWTManager) GenerateToken(userID uint, username, role string) (string,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
 }
 
-func (j *JWTManager) GenerateRefreshToken(userID uint, username, role string) (string, error) {
+func (j *JWTManager) GenerateRefreshToken(userID uint, username, role string, tokenVersion int) (string, error) {
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:       userID,
+		Username:     username,
+		Role:         role,
+		TokenType:    TokenTypeRefresh,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   username,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
+}
+
+// GenerateChallengeToken issues a short-lived token proving the password step of a two-step login
+func (j *JWTManager) GenerateChallengeToken(userID uint, username, role string) (string, error) {
+	claims := &Claims{
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenType: TokenTypeChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)), // 5 minutes
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   username,
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// GenerateClientToken issues an access token for an API client's service user
+func (j *JWTManager) GenerateClientToken(userID uint, username, role, clientID, scope string, ttl time.Duration) (string, error) {
+	claims := &Claims{
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenType: TokenTypeClient,
+		ClientID:  clientID,
+		Scope:     scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   username,
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// HasScope reports whether a client token was granted a scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *JWTManager) sign(claims *Claims) (string, error) {
+	if j.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.secretKey))
+	}
+
+	key, err := j.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -72,3 +175,34 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// verificationKey picks the key for a token and pins the algorithm to that key
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok && j.keys != nil {
+		key, err := j.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.signingMethod().Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.Private.Public(), nil
+	}
+
+	if token.Method.Alg() != jwt.SigningMethodHS256.Alg() || j.secretKey == "" {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return []byte(j.secretKey), nil
+}
+
+// JWKS returns the public keys that can verify unexpired tokens
+func (j *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if j.keys == nil {
+		return set
+	}
+	for _, key := range j.keys.VerificationKeys() {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"tiger-fasttrack-card/internal/i18n"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordPolicy validates new passwords and hashes them at the configured cost
+type PasswordPolicy struct {
+	MinLength     int // in characters, so a Thai password isn't counted per UTF-8 byte
+	MaxLength     int // in bytes; bcrypt ignores anything past 72 bytes
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	HistorySize   int // number of previous passwords that can't be reused
+	HashCost      int
+
+	breached map[string]struct{} // upper-case SHA-1 hex digests
+}
+
+// DefaultPasswordPolicy returns the policy used when nothing is configured
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:    8,
+		MaxLength:    72,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		HistorySize:  5,
+		HashCost:     12,
+	}
+}
+
+// LoadBreachList reads a list of known-breached passwords, one per line
+// Lines may be plain passwords or SHA-1 hex digests (optionally "HASH:count" as
+// in the Have I Been Pwned downloads). Blank lines and lines starting with # are skipped.
+func (p *PasswordPolicy) LoadBreachList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open breach list: %w", err)
+	}
+	defer file.Close()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if digest, ok := sha1Entry(line); ok {
+			breached[digest] = struct{}{}
+			continue
+		}
+		breached[sha1Hex(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read breach list: %w", err)
+	}
+
+	p.breached = breached
+	return nil
+}
+
+// Validate checks a new password against the policy
+func (p *PasswordPolicy) Validate(password, username string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return i18n.NewError("password_too_short", p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return i18n.NewError("password_too_long", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		return i18n.NewError("password_needs_uppercase")
+	}
+	if p.RequireLower && !hasLower {
+		return i18n.NewError("password_needs_lowercase")
+	}
+	if p.RequireDigit && !hasDigit {
+		return i18n.NewError("password_needs_digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return i18n.NewError("password_needs_symbol")
+	}
+
+	if containsUsername(password, username) {
+		return i18n.NewError("password_matches_username")
+	}
+
+	if _, found := p.breached[sha1Hex(password)]; found {
+		return i18n.NewError("password_breached")
+	}
+
+	return nil
+}
+
+// Hash hashes a password at the configured cost
+func (p *PasswordPolicy) Hash(password string) (string, error) {
+	return HashPassword(password, p.cost())
+}
+
+// NeedsRehash reports whether a stored hash is weaker than the configured cost
+func (p *PasswordPolicy) NeedsRehash(hash string) bool {
+	return NeedsRehash(hash, p.cost())
+}
+
+func (p *PasswordPolicy) cost() int {
+	if p.HashCost < bcrypt.MinCost || p.HashCost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return p.HashCost
+}
+
+// minUsernameLength is the shortest username a password may not contain; shorter ones
+// would rule out too many passwords, so they are only compared whole
+const minUsernameLength = 3
+
+func containsUsername(password, username string) bool {
+	if username == "" {
+		return false
+	}
+	if utf8.RuneCountInString(username) < minUsernameLength {
+		return strings.EqualFold(password, username)
+	}
+	return strings.Contains(strings.ToLower(password), strings.ToLower(username))
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// sha1Entry recognizes "HASH" or "HASH:count" lines
+func sha1Entry(line string) (string, bool) {
+	digest, _, _ := strings.Cut(line, ":")
+	if len(digest) != 40 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", false
+	}
+	return strings.ToUpper(digest), true
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tiger-fasttrack-card/internal/i18n"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breachList := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(breachList, []byte("Password123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	breaching := DefaultPasswordPolicy()
+	if err := breaching.LoadBreachList(breachList); err != nil {
+		t.Fatal(err)
+	}
+	symbols := DefaultPasswordPolicy()
+	symbols.RequireSymbol = true
+	thai := &PasswordPolicy{MinLength: 8, MaxLength: 72}
+
+	tests := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		username string
+		wantCode string
+	}{
+		{name: "valid", policy: DefaultPasswordPolicy(), password: "Tiger-Card-2025", username: "somchai"},
+		{name: "too short", policy: DefaultPasswordPolicy(), password: "Ab1", wantCode: "password_too_short"},
+		{name: "exactly the minimum", policy: DefaultPasswordPolicy(), password: "Abcdefg1"},
+		{name: "too long", policy: DefaultPasswordPolicy(), password: "Aa1" + string(make([]byte, 70)), wantCode: "password_too_long"},
+		{name: "Thai password counted in characters", policy: thai, password: "รหัสผ่านยาว"},
+		{name: "short Thai password", policy: thai, password: "รหัสผ่า", wantCode: "password_too_short"},
+		{name: "no uppercase", policy: DefaultPasswordPolicy(), password: "tiger-card-2025", wantCode: "password_needs_uppercase"},
+		{name: "no lowercase", policy: DefaultPasswordPolicy(), password: "TIGER-CARD-2025", wantCode: "password_needs_lowercase"},
+		{name: "no digit", policy: DefaultPasswordPolicy(), password: "Tiger-Card-Gold", wantCode: "password_needs_digit"},
+		{name: "no symbol", policy: symbols, password: "TigerCard2025", wantCode: "password_needs_symbol"},
+		{name: "symbol", policy: symbols, password: "TigerCard 2025"},
+		{name: "is the username", policy: DefaultPasswordPolicy(), password: "Somchai2025", username: "somchai2025", wantCode: "password_matches_username"},
+		{name: "contains the username", policy: DefaultPasswordPolicy(), password: "My-SomChai-2025", username: "somchai", wantCode: "password_matches_username"},
+		{name: "contains a short username", policy: DefaultPasswordPolicy(), password: "Tiger-Card-2025", username: "ti"},
+		{name: "breached", policy: breaching, password: "Password123", wantCode: "password_breached"},
+		{name: "not breached", policy: breaching, password: "Password124"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, tt.username)
+			var coded *i18n.Error
+			switch {
+			case tt.wantCode == "" && err != nil:
+				t.Errorf("Validate(%q) = %v, want nil", tt.password, err)
+			case tt.wantCode != "" && (!errors.As(err, &coded) || coded.Code != tt.wantCode):
+				t.Errorf("Validate(%q) = %v, want %s", tt.password, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestLoadBreachList(t *testing.T) {
+	list := "# comment\n\n  hunter2  \n" +
+		// SHA-1 of "password", as a bare digest and in the HIBP HASH:count format
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n" +
+		// SHA-1 of "letmein", lower-case with a count
+		"b7a875fc1ea228b9061041b7cec4bd3c52ab3ce3:42\n"
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy := &PasswordPolicy{}
+	if err := policy.LoadBreachList(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		breached bool
+	}{
+		{password: "hunter2", breached: true},
+		{password: "password", breached: true},
+		{password: "letmein", breached: true},
+		{password: "# comment"},
+		{password: "  hunter2  "},
+		{password: "correct horse battery staple"},
+	}
+	for _, tt := range tests {
+		err := policy.Validate(tt.password, "")
+		if got := err != nil; got != tt.breached {
+			t.Errorf("Validate(%q) = %v, want breached %v", tt.password, err, tt.breached)
+		}
+	}
+
+	if err := policy.LoadBreachList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadBreachList() of a missing file = nil")
+	}
+}
+
+func TestPasswordPolicyHash(t *testing.T) {
+	policy := &PasswordPolicy{HashCost: bcrypt.MinCost}
+	hash, err := policy.Hash("Tiger-Card-2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, _ := bcrypt.Cost([]byte(hash)); cost != bcrypt.MinCost {
+		t.Errorf("Hash() cost = %d, want %d", cost, bcrypt.MinCost)
+	}
+	if !CheckPassword("Tiger-Card-2025", hash) || CheckPassword("Tiger-Card-2026", hash) {
+		t.Error("CheckPassword() doesn't match the hash")
+	}
+
+	tests := []struct {
+		name string
+		cost int
+		hash string
+		want bool
+	}{
+		{name: "lower cost", cost: bcrypt.MinCost + 1, hash: hash, want: true},
+		{name: "current cost", cost: bcrypt.MinCost, hash: hash},
+		{name: "invalid cost falls back to the default", cost: 0, hash: hash, want: true},
+		{name: "not a bcrypt hash", cost: bcrypt.MinCost + 1, hash: "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &PasswordPolicy{HashCost: tt.cost}
+			if got := policy.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 4226 / RFC 6238 SHA-1 test key "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantStep int64
+		wantOK   bool
+	}{
+		// RFC 6238 appendix B, truncated to six digits
+		{name: "rfc 59", secret: rfcSecret, code: "287082", now: 59, wantStep: 1, wantOK: true},
+		{name: "rfc 1111111109", secret: rfcSecret, code: "081804", now: 1111111109, wantStep: 37037036, wantOK: true},
+		{name: "rfc 1234567890", secret: rfcSecret, code: "005924", now: 1234567890, wantStep: 41152263, wantOK: true},
+		{name: "rfc 2000000000", secret: rfcSecret, code: "279037", now: 2000000000, wantStep: 66666666, wantOK: true},
+		{name: "lowercase secret and spaces", secret: strings.ToLower(rfcSecret), code: " 287082 ", now: 59, wantStep: 1, wantOK: true},
+		{name: "previous step", secret: rfcSecret, code: "287082", now: 89, wantStep: 1, wantOK: true},
+		{name: "next step", secret: rfcSecret, code: "287082", now: 29, wantStep: 1, wantOK: true},
+		{name: "two steps late", secret: rfcSecret, code: "287082", now: 90},
+		{name: "wrong code", secret: rfcSecret, code: "123456", now: 59},
+		{name: "too short", secret: rfcSecret, code: "28708", now: 59},
+		{name: "invalid secret", secret: "not base32!", code: "287082", now: 59},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Errorf("secret %q decodes to %d bytes (%v), want 20", secret, len(key), err)
+	}
+	if other, _ := GenerateTOTPSecret(); other == secret {
+		t.Error("two secrets are equal")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(TOTPProvisioningURI("Tiger FastTrack Card", "alice", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Tiger FastTrack Card:alice" {
+		t.Errorf("URI = %s", uri)
+	}
+	query := uri.Query()
+	for key, want := range map[string]string{"secret": rfcSecret, "issuer": "Tiger FastTrack Card", "digits": "6", "period": "30"} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
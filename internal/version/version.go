@@ -0,0 +1,46 @@
+// Package version reports the build's version and commit. Release builds set them with
+// -ldflags, e.g.
+//
+//	go build -ldflags "-X tiger-fasttrack-card/internal/version.Version=1.4.0 -X tiger-fasttrack-card/internal/version.Commit=$(git rev-parse --short HEAD)"
+//
+// Other builds fall back to the VCS revision the Go toolchain records.
+package version
+
+import "runtime/debug"
+
+// Set at build time with -ldflags "-X tiger-fasttrack-card/internal/version.<Name>=..."
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// Get returns the build information, reading the commit from the binary when it wasn't injected
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime}
+	if info.Commit != "" {
+		return info
+	}
+
+	info.Commit = "unknown"
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
@@ -2,52 +2,116 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"tiger-fasttrack-card/internal/captcha"
+	"tiger-fasttrack-card/internal/cli"
 	"tiger-fasttrack-card/internal/config"
 	"tiger-fasttrack-card/internal/database"
 	"tiger-fasttrack-card/internal/handlers"
+	"tiger-fasttrack-card/internal/health"
+	"tiger-fasttrack-card/internal/keys"
+	"tiger-fasttrack-card/internal/lockout"
+	"tiger-fasttrack-card/internal/logging"
+	"tiger-fasttrack-card/internal/metrics"
 	"tiger-fasttrack-card/internal/middleware"
 	"tiger-fasttrack-card/internal/migrations"
+	"tiger-fasttrack-card/internal/notify"
+	"tiger-fasttrack-card/internal/oidc"
+	"tiger-fasttrack-card/internal/ratelimit"
 	"tiger-fasttrack-card/internal/repository"
 	"tiger-fasttrack-card/internal/routes"
 	"tiger-fasttrack-card/internal/service"
+	"tiger-fasttrack-card/internal/tracing"
+	"tiger-fasttrack-card/internal/utils"
+	"tiger-fasttrack-card/internal/version"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Note: Using only environment variables from DigitalOcean App Platform
-	// No .env file loading needed in production
-	log.Println("Starting Tiger FastTrack Card API...")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file; environment variables override it (default $CONFIG_FILE)")
+	flag.Usage = usage
+	flag.Parse()
 
 	// Initialize configuration
-	cfg := config.New()
-	log.Printf("Environment: %s", cfg.Environment)
-	log.Printf("Port: %s", cfg.Port)
-	log.Printf("Database Host: %s", cfg.Database.Host)
-	log.Printf("Database SSL Mode: %s", cfg.Database.SSLMode)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		exitInvalidConfig(err)
+	}
+	if flag.NArg() > 0 && (flag.Arg(0) != "serve" || flag.NArg() > 1) {
+		os.Exit(runCommand(cfg, flag.Args()))
+	}
+	if err := cfg.Validate(); err != nil {
+		exitInvalidConfig(err)
+	}
+
+	// Initialize structured logging; the standard log package writes through it too
+	logger, err := logging.New(os.Stdout, logging.Options{Level: cfg.Logging.Level, Format: cfg.Logging.Format})
+	if err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	slog.SetDefault(logger)
+	build := version.Get()
+	slog.Info("Starting Tiger FastTrack Card API...",
+		"version", build.Version,
+		"commit", build.Commit,
+		"environment", cfg.Environment,
+		"port", cfg.Port,
+		"database_host", cfg.Database.Host,
+		"database_sslmode", cfg.Database.SSLMode,
+	)
+
+	// Initialize tracing; spans still buffered are flushed on shutdown
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		Environment: cfg.Environment,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	if cfg.Tracing.Exporter != "none" {
+		slog.Info("Tracing configured", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
 
 	// Initialize database
-	log.Println("Connecting to database...")
+	slog.Info("Connecting to database...")
 	db, err := database.New(cfg)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer db.Close()
-	log.Println("Database connection established successfully")
+	slog.Info("Database connection established successfully")
 
 	// Run database migrations
-	log.Println("Running database migrations...")
+	slog.Info("Running database migrations...")
 	if err := migrations.RunMigrations(db); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		fatal("Failed to migrate database", err)
 	}
-	log.Println("Database migrations completed successfully")
+	slog.Info("Database migrations completed successfully")
+
+	// Readiness checks: the database answers and is migrated to this build's schema
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Add("database", func(ctx context.Context) error {
+		sqlDB, err := db.GetDB().DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	checker.Add("migrations", func(ctx context.Context) error {
+		return migrations.CheckPending(ctx, db)
+	})
 
 	// Set Gin mode
 	if cfg.Environment == "production" {
@@ -57,54 +121,411 @@ func main() {
 	// Initialize Gin router
 	router := gin.New()
 
+	// Only believe forwarded client addresses from our own load balancers
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("Invalid trusted proxies", err)
+	}
+	router.TrustedPlatform = cfg.Server.ClientIPHeader
+
 	// Add middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(middleware.CORS())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestLogger())
+	// Metrics wraps Recovery so requests that panic are counted as the 500s they return
+	if cfg.Metrics.Enabled {
+		router.Use(middleware.Metrics())
+	}
+	router.Use(middleware.Recovery())
+	router.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		MaxAge:           cfg.CORS.MaxAge,
+	}))
+	router.Use(middleware.SecurityHeaders(cfg.Server.HSTSMaxAge))
+	router.Use(middleware.Language())
 
 	// Initialize repository
 	repo := repository.New(db)
 
-	// Initialize service with JWT secret
-	svc := service.New(repo, cfg.JWTSecret)
+	// Initialize login brute-force protection
+	loginGuard := newLoginGuard(cfg, db)
+	slog.Info("Login attempt store configured", "store", cfg.Lockout.Store)
+
+	// Initialize password policy
+	passwordPolicy, err := newPasswordPolicy(cfg)
+	if err != nil {
+		fatal("Failed to load password breach list", err)
+	}
+	if cfg.Password.BreachListPath != "" {
+		slog.Info("Password breach list loaded", "path", cfg.Password.BreachListPath)
+	}
+
+	// Initialize notifier for password reset emails (nil disables password reset)
+	var notifier notify.Notifier
+	switch cfg.Notifier.Driver {
+	case "smtp":
+		notifier = notify.NewSMTPNotifier(cfg.Notifier.SMTPHost, cfg.Notifier.SMTPPort,
+			cfg.Notifier.SMTPUsername, cfg.Notifier.SMTPPassword, cfg.Notifier.SMTPFrom)
+	case "log":
+		notifier = notify.NewLogNotifier(cfg.Notifier.FilePath)
+	}
+	slog.Info("Notifier configured", "driver", cfg.Notifier.Driver)
+
+	// Initialize SMS delivery for phone verification codes
+	var smsNotifier notify.Notifier
+	switch cfg.Notifier.SMSDriver {
+	case "webhook":
+		smsNotifier = notify.NewWebhookNotifier(cfg.Notifier.SMSWebhookURL, cfg.Notifier.SMSWebhookToken)
+	case "log":
+		smsNotifier = notify.NewLogNotifier(cfg.Notifier.FilePath)
+	}
+
+	// Initialize registration
+	registration := service.RegistrationOptions{
+		Mode:            cfg.Register.Mode,
+		VerificationTTL: cfg.Register.VerificationTTL,
+		InvitationTTL:   cfg.Register.InvitationTTL,
+		EmailNotifier:   notifier,
+		SMSNotifier:     smsNotifier,
+	}
+	if cfg.Register.Verification != "none" {
+		registration.Verification = cfg.Register.Verification
+	}
+	if cfg.Captcha.Provider != "none" {
+		verifier, err := captcha.NewVerifier(cfg.Captcha.Provider, cfg.Captcha.Secret, cfg.Captcha.VerifyURL)
+		if err != nil {
+			fatal("Failed to initialize CAPTCHA verifier", err)
+		}
+		registration.Captcha = verifier
+	}
+	slog.Info("Registration configured", "mode", cfg.Register.Mode,
+		"verification", cfg.Register.Verification, "captcha", cfg.Captcha.Provider)
+
+	// Initialize JWT signing
+	var jwtManager *utils.JWTManager
+	if cfg.JWT.Algorithm == "HS256" {
+		jwtManager = utils.NewJWTManager(cfg.JWTSecret)
+	} else {
+		encryptionKey, err := keys.ParseEncryptionKey(cfg.JWT.KeyEncryptionKey)
+		if err != nil {
+			fatal("Invalid JWT_KEY_ENCRYPTION_KEY", err)
+		}
+		keyRing := keys.NewRing()
+		rotator := keys.NewRotator(db, keyRing, keys.RotationPolicy{
+			Algorithm:  cfg.JWT.Algorithm,
+			Interval:   cfg.JWT.RotationInterval,
+			Retention:  cfg.JWT.Retention,
+			Prepublish: cfg.JWT.Prepublish,
+		}, encryptionKey)
+		if err := rotator.Sync(); err != nil {
+			fatal("Failed to load JWT signing keys", err)
+		}
+		rotationCtx, stopRotation := context.WithCancel(context.Background())
+		defer stopRotation()
+		go rotator.Run(rotationCtx, cfg.JWT.SyncInterval)
+
+		legacySecret := ""
+		if cfg.JWT.AcceptLegacyHS256 {
+			legacySecret = cfg.JWTSecret
+		}
+		jwtManager = utils.NewJWTManagerWithKeys(keyRing, legacySecret)
+	}
+	slog.Info("JWT signing configured", "algorithm", cfg.JWT.Algorithm)
+
+	// Initialize single sign-on for staff
+	sso := service.OIDCOptions{
+		DefaultRole:             cfg.OIDC.DefaultRole,
+		LinkLocalAccounts:       cfg.OIDC.LinkLocalAccounts,
+		LocalLoginDisabledRoles: cfg.OIDC.LocalLoginDisabledRoles,
+	}
+	if cfg.OIDC.Enabled() {
+		discoveryCtx, cancelDiscovery := context.WithTimeout(context.Background(), 30*time.Second)
+		provider, err := oidc.NewProvider(discoveryCtx, oidc.Config{
+			IssuerURL:     cfg.OIDC.IssuerURL,
+			ClientID:      cfg.OIDC.ClientID,
+			ClientSecret:  cfg.OIDC.ClientSecret,
+			RedirectURL:   cfg.OIDC.RedirectURL,
+			Scopes:        cfg.OIDC.Scopes,
+			UsernameClaim: cfg.OIDC.UsernameClaim,
+			GroupsClaim:   cfg.OIDC.GroupsClaim,
+		})
+		cancelDiscovery()
+		if err != nil {
+			fatal("Failed to initialize OIDC provider", err)
+		}
+		sso.Provider = provider
+		for _, mapping := range cfg.OIDC.RoleMapping {
+			sso.RoleMapping = append(sso.RoleMapping, service.RoleMapping{Group: mapping.Group, Role: mapping.Role})
+		}
+		slog.Info("Single sign-on configured", "issuer", cfg.OIDC.IssuerURL)
+	}
+
+	// Initialize service
+	svc := service.New(repo, jwtManager, loginGuard, passwordPolicy, service.PasswordResetOptions{
+		Notifier: notifier,
+		TokenTTL: cfg.Reset.TokenTTL,
+		ResetURL: cfg.Reset.URL,
+	}, service.TwoFactorOptions{
+		Issuer:        cfg.TwoFactor.Issuer,
+		RequiredRoles: cfg.TwoFactor.RequiredRoles,
+	}, cfg.OAuth.ClientTokenTTL, sso, registration)
 
 	// Initialize handlers
-	h := handlers.New(svc)
+	h := handlers.New(svc, checker)
 
-	// Setup routes
-	routes.Setup(router, h, cfg.JWTSecret)
+	// Initialize rate limiter (nil disables throttling)
+	var limiter ratelimit.Store
+	if cfg.RateLimit.Enabled {
+		if cfg.RateLimit.Store == "redis" {
+			redisStore, err := ratelimit.NewRedisStore(cfg.RateLimit.RedisURL)
+			if err != nil {
+				fatal("Failed to initialize rate limiter", err)
+			}
+			defer redisStore.Close()
+			limiter = redisStore
+		} else {
+			limiter = ratelimit.NewMemoryStore()
+		}
+		slog.Info("Rate limiting configured", "store", cfg.RateLimit.Store)
+	}
 
-	// Start server with graceful shutdown
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = cfg.Port
+	// Export connection pool statistics
+	if cfg.Metrics.Enabled {
+		sqlDB, err := db.GetDB().DB()
+		if err != nil {
+			fatal("Failed to get database handle for metrics", err)
+		}
+		if err := metrics.RegisterDB(sqlDB, "postgres"); err != nil {
+			fatal("Failed to register database metrics", err)
+		}
 	}
 
+	// Setup routes
+	routes.Setup(router, h, cfg, jwtManager, limiter)
+
+	// Start server with graceful shutdown
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: router,
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
 
 	// Initializing the server in a goroutine so that
 	// it won't block the graceful shutdown handling below
 	go func() {
-		log.Printf("Server starting on port %s", port)
+		slog.Info("Server starting", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			fatal("Server failed", err)
 		}
 	}()
 
+	// Metrics on their own port stay off the public load balancer
+	var metricsSrv *http.Server
+	if cfg.Metrics.Enabled && cfg.Metrics.Port != "" {
+		metricsRouter := gin.New()
+		metricsRouter.Use(middleware.Recovery())
+		routes.SetupMetrics(metricsRouter, cfg)
+		metricsSrv = &http.Server{
+			Addr:              ":" + cfg.Metrics.Port,
+			Handler:           metricsRouter,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		}
+		go func() {
+			slog.Info("Metrics server starting", "port", cfg.Metrics.Port)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal("Metrics server failed", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server with a timeout.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Fail readiness first so load balancers stop sending new requests, then let the
+	// requests in flight finish
+	checker.SetDraining()
+	time.Sleep(cfg.Health.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown: ", err)
+		fatal("Server forced to shutdown", err)
+	}
+	if metricsSrv != nil {
+		metricsSrv.Shutdown(ctx)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+
+	slog.Info("Server exiting")
+}
+
+// runCommand runs a maintenance command instead of the server and returns the exit code
+func runCommand(cfg *config.Config, args []string) int {
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+		redacted := flags.Bool("redacted", false, "mask secrets and credentials in URLs")
+		if err := flags.Parse(args[2:]); err != nil {
+			return 2
+		}
+		if err := config.Print(os.Stdout, cfg, *redacted); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		// Still print a config that can't start the server, then say why
+		if err := cfg.Validate(); err != nil {
+			printConfigErrors(err)
+			return 1
+		}
+		return 0
+	}
+
+	switch args[0] {
+	case "migrate", "user", "card", "seed":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", strings.Join(args, " "))
+		usage()
+		return 2
+	}
+
+	// The remaining commands work on the database with the server's configuration; logs
+	// go to stderr so they don't mix with the command's output
+	if err := cfg.Validate(); err != nil {
+		printConfigErrors(err)
+		return 1
+	}
+	logger, err := logging.New(os.Stderr, logging.Options{Level: cfg.Logging.Level, Format: cfg.Logging.Format})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid logging configuration:", err)
+		return 1
+	}
+	slog.SetDefault(logger)
+
+	db, err := database.New(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if args[0] == "migrate" {
+		if len(args) > 1 {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", strings.Join(args, " "))
+			return 2
+		}
+		if err := migrations.RunMigrations(db); err != nil {
+			slog.Error("Failed to migrate database", "error", err)
+			return 1
+		}
+		fmt.Printf("Database migrated to schema version %d\n", migrations.SchemaVersion)
+		return 0
 	}
+	if err := migrations.CheckPending(ctx, db); err != nil {
+		slog.Error("Database isn't migrated, run the migrate command first", "error", err)
+		return 1
+	}
+
+	passwordPolicy, err := newPasswordPolicy(cfg)
+	if err != nil {
+		slog.Error("Failed to load password breach list", "error", err)
+		return 1
+	}
+
+	// Commands don't issue tokens or send messages, so those services are left unconfigured
+	repo := repository.New(db)
+	svc := service.New(repo, nil, newLoginGuard(cfg, db), passwordPolicy, service.PasswordResetOptions{},
+		service.TwoFactorOptions{}, cfg.OAuth.ClientTokenTTL,
+		service.OIDCOptions{LocalLoginDisabledRoles: cfg.OIDC.LocalLoginDisabledRoles}, service.RegistrationOptions{})
+
+	return cli.Run(ctx, cli.Env{
+		Config:  cfg,
+		Repo:    repo,
+		Service: svc,
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}, args)
+}
+
+// newLoginGuard builds the login brute-force protection the server and commands share
+func newLoginGuard(cfg *config.Config, db *database.Database) *lockout.Guard {
+	var attemptStore lockout.Store
+	if cfg.Lockout.Store == "postgres" {
+		attemptStore = lockout.NewPostgresStore(db)
+	} else {
+		attemptStore = lockout.NewMemoryStore()
+	}
+	return lockout.NewGuard(attemptStore,
+		lockout.Policy{
+			MaxAttempts: cfg.Lockout.MaxAttempts,
+			Window:      cfg.Lockout.Window,
+			BaseLockout: cfg.Lockout.BaseLockout,
+			MaxLockout:  cfg.Lockout.MaxLockout,
+		},
+		lockout.Policy{
+			MaxAttempts: cfg.Lockout.IPMaxAttempts,
+			Window:      cfg.Lockout.Window,
+			BaseLockout: cfg.Lockout.BaseLockout,
+			MaxLockout:  cfg.Lockout.MaxLockout,
+		},
+	)
+}
+
+// newPasswordPolicy builds the configured password policy
+func newPasswordPolicy(cfg *config.Config) (*utils.PasswordPolicy, error) {
+	passwordPolicy := utils.DefaultPasswordPolicy()
+	passwordPolicy.MinLength = cfg.Password.MinLength
+	passwordPolicy.RequireUpper = cfg.Password.RequireUpper
+	passwordPolicy.RequireLower = cfg.Password.RequireLower
+	passwordPolicy.RequireDigit = cfg.Password.RequireDigit
+	passwordPolicy.RequireSymbol = cfg.Password.RequireSymbol
+	passwordPolicy.HistorySize = cfg.Password.HistorySize
+	passwordPolicy.HashCost = cfg.Password.BcryptCost
+	if cfg.Password.BreachListPath != "" {
+		if err := passwordPolicy.LoadBreachList(cfg.Password.BreachListPath); err != nil {
+			return nil, err
+		}
+	}
+	return passwordPolicy, nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: tiger-fasttrack-card [-config file] [serve]                   start the server, migrating the database first")
+	fmt.Fprintln(os.Stderr, "       tiger-fasttrack-card [-config file] config print [--redacted]  print the effective configuration")
+	fmt.Fprintln(os.Stderr, "       tiger-fasttrack-card [-config file] migrate                    migrate the database and exit")
+	for _, line := range strings.Split(cli.Usage, "\n") {
+		fmt.Fprintln(os.Stderr, "       tiger-fasttrack-card [-config file] "+line)
+	}
+	flag.PrintDefaults()
+}
+
+// exitInvalidConfig lists every configuration problem and exits; it runs before logging is set up
+func exitInvalidConfig(err error) {
+	printConfigErrors(err)
+	os.Exit(1)
+}
+
+func printConfigErrors(err error) {
+	fmt.Fprintln(os.Stderr, "Invalid configuration:")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintln(os.Stderr, "  - "+line)
+	}
+}
 
-	log.Println("Server exiting")
+// fatal logs err and exits; deferred cleanup doesn't run
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
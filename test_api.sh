@@ -5,7 +5,7 @@
 
 BASE_URL="http://localhost:8080"
 TEST_EMAIL="user@example.com"
-TEST_PASSWORD="password123"
+TEST_PASSWORD="Password123"
 
 echo "🚀 Tiger FastTrack Card API - Testing New Endpoints"
 echo "=================================================="